@@ -3,7 +3,11 @@ module github.com/defenseunicorns/component-generator
 go 1.20
 
 require (
+	github.com/Masterminds/semver/v3 v3.2.1
+	github.com/bmatcuk/doublestar/v4 v4.8.1
 	github.com/google/uuid v1.3.1
+	github.com/klauspost/compress v1.17.9
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.8.4
 	gopkg.in/yaml.v2 v2.4.0
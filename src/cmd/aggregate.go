@@ -6,9 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/defenseunicorns/component-generator/src/internal/cache"
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
 	"github.com/defenseunicorns/component-generator/src/internal/types"
 	"github.com/defenseunicorns/component-generator/src/pkg/component"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -16,20 +20,33 @@ import (
 const oscalVer = "1.0.4"
 
 var (
-	input   string
-	name    string
-	version string
-	title   string
-	stdout  bool
-	remotes []string
-	locals  []string
+	input         string
+	name          string
+	format        string
+	version       string
+	title         string
+	stdout        bool
+	remotes       []string
+	locals        []string
+	locked        bool
+	offline       bool
+	cacheDir      string
+	concurrency   int
+	timeout       time.Duration
+	retries       int
+	validate      string
+	deterministic bool
+	canonical     bool
+	duplicates    string
+	imports       string
+	importDepth   int
 )
 
 // aggregateCmd represents the aggregate command
 var aggregateCmd = &cobra.Command{
 	Use:   "aggregate",
 	Short: "aggregate a collection of component definition files and produce a single artifact",
-	Long: `This command aggregates local or remote component-definition OSCAL yaml files.
+	Long: `This command aggregates local or remote component-definition OSCAL yaml, json or xml files.
 	The purpose of creating a single concise artifact for platforms or other systems of aggregate software components.
 	`,
 	Args: cobra.ExactArgs(0),
@@ -44,10 +61,23 @@ func init() {
 	aggregateCmd.Flags().BoolVarP(&stdout, "stdout", "s", false, "print to stdout rather than the declaratively specified filename")
 	aggregateCmd.Flags().StringVarP(&input, "input", "i", "", "Path to the file to be processed")
 	aggregateCmd.Flags().StringVarP(&name, "name", "n", "", "Path/Name of the file to be created")
+	aggregateCmd.Flags().StringVarP(&format, "format", "f", "", "format of the file to be created - yaml, json or xml (default inferred from its name, otherwise yaml)")
 	aggregateCmd.Flags().StringVarP(&version, "file-version", "v", "", "the version of the document to be created")
 	aggregateCmd.Flags().StringVarP(&title, "title", "t", "", "the title of the document to be created")
 	aggregateCmd.Flags().StringArrayVarP(&locals, "local", "l", []string{}, "path to a local component file - component.yaml")
-	aggregateCmd.Flags().StringArrayVarP(&remotes, "remote", "r", []string{}, "path to a remote component file - REPO_URI[.git]/PKG_PATH[@VERSION]")
+	aggregateCmd.Flags().StringArrayVarP(&remotes, "remote", "r", []string{}, "path to a remote component file - REPO[.git]//PATH@REF, REPO.git/PATH@REF or github.com/ORG/REPO/PATH@REF")
+	aggregateCmd.Flags().BoolVar(&offline, "offline", false, "serve remote components only from the cache, failing if any are missing")
+	aggregateCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "directory remote components are cached in (default $XDG_CACHE_HOME/component-generator)")
+	aggregateCmd.Flags().IntVar(&concurrency, "concurrency", 4, "maximum number of components to fetch at once")
+	aggregateCmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "timeout for each HTTP request")
+	aggregateCmd.Flags().IntVar(&retries, "retries", 3, "number of times an HTTP request that failed with a network error, 5xx or 429 is retried")
+	aggregateCmd.Flags().StringVar(&validate, "validate", "warn", "validate every component and the generated file against the OSCAL JSON schema - strict fails on violations, warn reports them, off skips validation")
+	aggregateCmd.Flags().BoolVar(&deterministic, "deterministic", false, "derive the document's uuid and last-modified from its inputs, so identical inputs produce identical output")
+	aggregateCmd.Flags().BoolVar(&canonical, "canonical", false, "sort components, control implementations, requirements and back-matter resources by stable keys and normalize whitespace")
+	aggregateCmd.Flags().StringVar(&duplicates, "duplicates", "", "policy for components defined by more than one source - error, keep-first, keep-last or deep-merge (default error)")
+	aggregateCmd.Flags().StringVar(&imports, "imports", "", "what to do with the import-component-definitions of components - preserve carries them over, resolve aggregates the imported definitions (default preserve)")
+	aggregateCmd.Flags().IntVar(&importDepth, "import-depth", 0, "maximum number of levels of imports resolved with --imports resolve (default 8)")
+	aggregateCmd.Flags().BoolVar(&locked, "locked", false, "fail if any source resolves differently than recorded in the lockfile next to the input file")
 
 }
 
@@ -79,11 +109,16 @@ func run() {
 		for _, v := range remotes {
 			var remote types.Remote
 
-			repoSplit := strings.Split(v, ".git")
-			verSplit := strings.Split(repoSplit[1], "@")
+			src, err := source.ParseGitReference(v)
+			if err != nil {
+				log.Fatalf("invalid --remote %q: %v", v, err)
+			}
+			if src.Path == "" {
+				log.Fatalf("invalid --remote %q: the path of the component file is required - expected REPO[.git]//PATH@REF", v)
+			}
 
-			remote.Git = repoSplit[0] + ".git@" + verSplit[1]
-			remote.Path = "." + verSplit[0]
+			remote.Git = src.Location + "@" + src.Ref
+			remote.Path = src.Path
 
 			config.Components.Remotes = append(config.Components.Remotes, remote)
 		}
@@ -113,23 +148,114 @@ func run() {
 		}
 	}
 
+	if format != "" {
+		config.Format = format
+	}
+	if deterministic {
+		config.Deterministic = true
+	}
+	if canonical {
+		config.Canonical = true
+	}
+	if duplicates != "" {
+		config.Duplicates = duplicates
+	}
+	if _, err := component.ParseDuplicatePolicy(config.Duplicates); err != nil {
+		log.Fatal(err)
+	}
+	if imports != "" {
+		config.Imports = imports
+	}
+	if _, err := component.ParseImportPolicy(config.Imports); err != nil {
+		log.Fatal(err)
+	}
+	if importDepth > 0 {
+		config.ImportDepth = importDepth
+	}
+	if _, err := component.OutputFormat(config); err != nil {
+		log.Fatal(err)
+	}
+	switch validate {
+	case "strict", "warn", "off":
+	default:
+		log.Fatalf("invalid --validate %q - must be one of strict, warn or off", validate)
+	}
+
 	config.BaseDirectory, _ = filepath.Split(path)
+	config.Offline = offline
+	config.Concurrency = concurrency
+	config.Timeout = timeout
+	config.Retries = retries
+	config.CacheDirectory = cacheDir
+	if config.CacheDirectory == "" {
+		dir, err := cache.DefaultDir()
+		if err != nil {
+			log.Printf("unable to determine cache directory - caching disabled: %v", err)
+		}
+		config.CacheDirectory = dir
+	}
+
+	if locked && path == "" {
+		log.Fatal("--locked requires a declarative config specified with --input")
+	}
+
+	documents, err := component.FetchDocuments(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, doc := range documents {
+		switch source := doc.Source.String(); {
+		case doc.Name != source:
+			fmt.Fprintf(os.Stderr, "%s resolved to %s (%s)\n", source, doc.Name, doc.Resolved)
+		case doc.Resolved != "" && doc.Resolved != doc.Source.Location:
+			fmt.Fprintf(os.Stderr, "%s resolved to %s\n", source, doc.Resolved)
+		}
+	}
+
+	// The lockfile lives alongside a declarative config - oscal-components.yaml is locked by oscal-components.lock
+	lock := component.NewLockfile(documents)
+	lockPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".lock"
+	if locked {
+		existingLock, err := readLockfile(lockPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := component.VerifyLockfile(existingLock, lock); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	yamlDoc, oscalObj, err := component.BuildOscalDocument(config)
+	if validate != "off" {
+		reportValidation(component.ValidateDocuments(documents))
+	}
+
+	collisions, err := component.Collisions(documents)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if policy, _ := component.ParseDuplicatePolicy(config.Duplicates); policy != component.DuplicateError {
+		for _, collision := range collisions {
+			fmt.Fprintf(os.Stderr, "%s - applying %s\n", collision, policy)
+		}
+	}
+
+	doc, oscalObj, err := component.AggregateDocuments(config, documents)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if validate != "off" {
+		reportValidation(component.ValidateOutput(config, doc))
+	}
 	_, error := os.Stat(config.Name)
 	if error == nil {
 		// if the file exists - read/unmarshall and compare
 		fmt.Println("File exists - running comparison")
-		var existingObj types.OscalComponentDocument
 		rawExist, err := os.ReadFile(config.Name)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		err = yaml.Unmarshal(rawExist, &existingObj)
+		existingObj, err := oscal.ParseComponentDocumentAs(oscal.DetectFormat(config.Name, rawExist), rawExist)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -142,29 +268,74 @@ func run() {
 			// If not modified, no need to write new file
 			fmt.Println("No fields have been updated - not updating document")
 			if stdout {
-				fmt.Print(string(yamlDoc))
+				fmt.Print(string(doc))
 			}
 		} else {
 			if !stdout {
-				err := os.WriteFile(config.Name, []byte(yamlDoc), 0644)
+				err := os.WriteFile(config.Name, []byte(doc), 0644)
 				if err != nil {
 					log.Fatalf("writing output: %s", err)
 				}
 			} else {
-				fmt.Print(string(yamlDoc))
+				fmt.Print(string(doc))
 			}
 		}
 
 	} else {
 		fmt.Println("File does not exist - running output")
 		if !stdout {
-			err := os.WriteFile(config.Name, []byte(yamlDoc), 0644)
+			err := os.WriteFile(config.Name, []byte(doc), 0644)
 			if err != nil {
 				log.Fatalf("writing output: %s", err)
 			}
 		} else {
-			fmt.Print(string(yamlDoc))
+			fmt.Print(string(doc))
+		}
+	}
+
+	// The lockfile is only replaced once the run has succeeded
+	if path != "" && !locked {
+		if err := writeLockfile(lockPath, lock); err != nil {
+			log.Fatal(err)
 		}
 	}
+}
 
+// reportValidation fails the run on schema violations in strict mode, otherwise only printing them.
+func reportValidation(err error) {
+	if err == nil {
+		return
+	}
+	if validate == "strict" {
+		log.Fatal(err)
+	}
+	log.Printf("warning: %v", err)
+}
+
+func readLockfile(path string) (types.Lockfile, error) {
+	var lock types.Lockfile
+
+	rawLock, err := os.ReadFile(path)
+	if err != nil {
+		return lock, fmt.Errorf("reading lockfile: %w", err)
+	}
+	err = yaml.Unmarshal(rawLock, &lock)
+	if err != nil {
+		return lock, fmt.Errorf("parsing lockfile %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+func writeLockfile(path string, lock types.Lockfile) error {
+	rawLock, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	rawLock = append([]byte("# Generated by component-generator aggregate - do not edit\n"), rawLock...)
+
+	err = os.WriteFile(path, rawLock, 0644)
+	if err != nil {
+		return fmt.Errorf("writing lockfile: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/defenseunicorns/component-generator/src/pkg/component"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate FILE...",
+	Short: "validate component definition files against the OSCAL schema and check their references",
+	Long: `This command validates OSCAL component-definition yaml, json or xml files against the OSCAL JSON schema of their version.
+	It also checks that uuids are well formed and unique, and that referenced roles, parties, locations, components and back-matter resources are defined.
+	Each finding is printed with its file and JSONPath, and the command exits non-zero if there are any.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runValidate(args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(files []string) int {
+	var findings []component.Finding
+	invalid := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			findings = append(findings, component.Finding{File: file, Path: "$", Message: err.Error()})
+			invalid++
+			continue
+		}
+		fileFindings := component.CheckFile(file, data)
+		if len(fileFindings) > 0 {
+			invalid++
+		}
+		findings = append(findings, fileFindings...)
+	}
+
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if len(findings) > 0 {
+		fmt.Fprintf(os.Stderr, "%d finding(s) in %d of %d file(s)\n", len(findings), invalid, len(files))
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d file(s) valid\n", len(files))
+	return 0
+}
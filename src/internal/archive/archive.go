@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// WalkFunc is called for every regular file in an archive, named by its path prefixed with any enclosing archives.
+type WalkFunc func(name string, r io.Reader) error
+
+// SkipFunc reports whether an entry, named as for WalkFunc, should be neither read nor descended into.
+type SkipFunc func(name string) bool
+
+// CleanPath makes a user supplied path relative to the root of an archive or git tree, so that it cannot escape it.
+func CleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// IsArchive reports whether a file name has the extension of a tar archive this package can read.
+func IsArchive(name string) bool {
+	for _, ext := range []string{".tar", ".tar.zst", ".tzst", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkFile walks the tar archive at path. See Walk.
+func WalkFile(path string, skip SkipFunc, fn WalkFunc) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Walk(f, skip, fn)
+}
+
+// Walk calls fn for every regular file in a tar archive, descending into nested archives. skip may be nil.
+func Walk(r io.Reader, skip SkipFunc, fn WalkFunc) error {
+	return walk(r, "", skip, fn)
+}
+
+func walk(r io.Reader, prefix string, skip SkipFunc, fn WalkFunc) error {
+	tr, closeFn, err := newTarReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read archive %s: %w", strings.TrimSuffix(prefix, "/"), err)
+	}
+	defer closeFn()
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive %s: %w", strings.TrimSuffix(prefix, "/"), err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := prefix + strings.TrimPrefix(header.Name, "./")
+		if skip != nil && skip(name) {
+			continue
+		}
+		if IsArchive(name) {
+			if err := walk(tr, name+"/", skip, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// newTarReader returns a tar reader over a stream compressed with gzip, zstd or nothing, detected by magic bytes.
+func newTarReader(r io.Reader) (*tar.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tar.NewReader(gz), func() { gz.Close() }, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tar.NewReader(zr), zr.Close, nil
+	default:
+		return tar.NewReader(br), func() {}, nil
+	}
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+)
+
+// The supported credential types. Each determines the headers sent to the host.
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeBearer = "bearer"
+	TypeBasic  = "basic"
+)
+
+// Credential is a secret for a single host and the way it is presented to that host.
+type Credential struct {
+	Type     string
+	Username string
+	// Password holds the token for token based types
+	Password string
+}
+
+// Lookup resolves the credential for a host from the config, then the forge token variables, then the netrc file.
+func Lookup(host string, entries []types.Auth) (Credential, bool, error) {
+	host = strings.ToLower(host)
+	if host == "" {
+		return Credential{}, false, nil
+	}
+
+	forge := forgeType(host)
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Host, host) {
+			continue
+		}
+		// An entry without secrets only declares the forge of a host, such as a self-hosted GitLab, so that the
+		// forge token applies to it
+		if entry.TokenEnv == "" && entry.UsernameEnv == "" && entry.PasswordEnv == "" && entry.Type != "" {
+			forge = strings.ToLower(entry.Type)
+			break
+		}
+		return fromConfig(entry)
+	}
+
+	switch forge {
+	case TypeGitHub:
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			return Credential{Type: TypeGitHub, Password: token}, true, nil
+		}
+	case TypeGitLab:
+		if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+			return Credential{Type: TypeGitLab, Password: token}, true, nil
+		}
+	}
+
+	return lookupNetrc(host)
+}
+
+// fromConfig resolves a config entry, reading the secrets from the environment variables it names.
+func fromConfig(entry types.Auth) (Credential, bool, error) {
+	cred := Credential{Type: strings.ToLower(entry.Type)}
+	if cred.Type == "" {
+		cred.Type = forgeType(strings.ToLower(entry.Host))
+	}
+
+	read := func(name string) (string, error) {
+		if name == "" {
+			return "", nil
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("auth for %s references environment variable %s, which is not set", entry.Host, name)
+		}
+		return value, nil
+	}
+
+	var err error
+	if cred.Username, err = read(entry.UsernameEnv); err != nil {
+		return cred, false, err
+	}
+	switch cred.Type {
+	case TypeGitHub, TypeGitLab, TypeBearer:
+		cred.Password, err = read(entry.TokenEnv)
+	case TypeBasic:
+		cred.Password, err = read(entry.PasswordEnv)
+	default:
+		return cred, false, fmt.Errorf("auth for %s has unsupported type %q - expected one of github, gitlab, bearer, basic", entry.Host, entry.Type)
+	}
+	if err != nil {
+		return cred, false, err
+	}
+	return cred, true, nil
+}
+
+// gitLabHosts are well known self-hosted GitLab instances whose names do not give them away.
+var gitLabHosts = map[string]bool{
+	"repo1.dso.mil": true,
+}
+
+// forgeType infers the credential type from well known forge host names, defaulting to bearer.
+func forgeType(host string) string {
+	switch {
+	case host == "github.com", strings.HasSuffix(host, ".github.com"), host == "raw.githubusercontent.com", host == "ghcr.io":
+		return TypeGitHub
+	case host == "gitlab.com", strings.HasPrefix(host, "gitlab."), strings.Contains(host, ".gitlab."), gitLabHosts[host]:
+		return TypeGitLab
+	default:
+		return TypeBearer
+	}
+}
+
+// HTTPHeader returns the headers that authenticate a plain HTTP request, such as downloading a raw file, to the host.
+func (c Credential) HTTPHeader() http.Header {
+	header := http.Header{}
+	switch c.Type {
+	case TypeGitLab:
+		header.Set("PRIVATE-TOKEN", c.Password)
+	case TypeGitHub, TypeBearer:
+		header.Set("Authorization", "Bearer "+c.Password)
+	case TypeBasic:
+		header.Set("Authorization", "Basic "+basic(c.Username, c.Password))
+	}
+	return header
+}
+
+// BasicAuth returns a username and password, pairing forge tokens with the username each forge expects.
+func (c Credential) BasicAuth() (string, string) {
+	username := c.Username
+	if username == "" {
+		switch c.Type {
+		case TypeGitHub:
+			username = "x-access-token"
+		case TypeGitLab:
+			username = "oauth2"
+		}
+	}
+	return username, c.Password
+}
+
+// BasicHeader returns the `Authorization` header value that presents the credential as BasicAuth does.
+func (c Credential) BasicHeader() string {
+	return "Basic " + basic(c.BasicAuth())
+}
+
+// GitHeader returns the `Authorization` header value git should send to the host.
+func (c Credential) GitHeader() string {
+	if c.Type == TypeBearer {
+		return "Bearer " + c.Password
+	}
+	return c.BasicHeader()
+}
+
+func basic(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
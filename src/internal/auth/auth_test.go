@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetrc(t *testing.T) {
+	t.Parallel()
+
+	machines := parseNetrc(`# comment
+machine repo1.dso.mil login robot password s3cret
+macdef init
+cd /pub
+machine ignored.example.com login nobody
+
+machine Example.com
+  login user
+  account acct
+  password pass
+default login anonymous password guest
+`)
+
+	require.Equal(t, Credential{Type: TypeBasic, Username: "robot", Password: "s3cret"}, machines["repo1.dso.mil"])
+	require.Equal(t, Credential{Type: TypeBasic, Username: "user", Password: "pass"}, machines["example.com"])
+	require.Equal(t, Credential{Type: TypeBasic, Username: "anonymous", Password: "guest"}, machines[""])
+	require.NotContains(t, machines, "ignored.example.com")
+}
+
+func TestLookup(t *testing.T) {
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte("machine repo1.dso.mil login robot password from-netrc\n"), 0600))
+	t.Setenv("NETRC", netrc)
+	t.Setenv("GITHUB_TOKEN", "from-github-env")
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("REPO1_TOKEN", "from-config")
+
+	entries := []types.Auth{{Host: "repo1.dso.mil", Type: "gitlab", TokenEnv: "REPO1_TOKEN"}}
+
+	// The config takes precedence over the netrc
+	cred, ok, err := Lookup("repo1.dso.mil", entries)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "from-config", cred.HTTPHeader().Get("PRIVATE-TOKEN"))
+
+	cred, ok, err = Lookup("repo1.dso.mil", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Credential{Type: TypeBasic, Username: "robot", Password: "from-netrc"}, cred)
+
+	cred, ok, err = Lookup("github.com", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "Bearer from-github-env", cred.HTTPHeader().Get("Authorization"))
+	username, password := cred.BasicAuth()
+	require.Equal(t, "x-access-token", username)
+	require.Equal(t, "from-github-env", password)
+
+	_, ok, err = Lookup("gitlab.com", nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// repo1.dso.mil is a known GitLab host, and an entry with only a type declares the forge of any other host
+	t.Setenv("GITLAB_TOKEN", "from-gitlab-env")
+	cred, ok, err = Lookup("repo1.dso.mil", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Credential{Type: TypeGitLab, Password: "from-gitlab-env"}, cred)
+
+	cred, ok, err = Lookup("code.example.com", []types.Auth{{Host: "code.example.com", Type: "gitlab"}})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "from-gitlab-env", cred.HTTPHeader().Get("PRIVATE-TOKEN"))
+
+	_, ok, err = Lookup("code.example.com", nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = Lookup("missing.example.com", []types.Auth{{Host: "missing.example.com", TokenEnv: "UNSET_COMPONENT_GENERATOR_TOKEN"}})
+	require.ErrorContains(t, err, "UNSET_COMPONENT_GENERATOR_TOKEN, which is not set")
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// netrcPath returns the location of the netrc file - $NETRC when set, otherwise ~/.netrc.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// lookupNetrc returns the login and password of the netrc machine entry for host, falling back to the default entry.
+func lookupNetrc(host string) (Credential, bool, error) {
+	path := netrcPath()
+	if path == "" {
+		return Credential{}, false, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Credential{}, false, nil
+	} else if err != nil {
+		return Credential{}, false, fmt.Errorf("reading netrc: %w", err)
+	}
+
+	machines := parseNetrc(string(content))
+	if cred, ok := machines[host]; ok {
+		return cred, true, nil
+	}
+	cred, ok := machines[""]
+	return cred, ok, nil
+}
+
+// parseNetrc parses the machine entries of a netrc file, storing the default entry under the empty name.
+func parseNetrc(content string) map[string]Credential {
+	machines := map[string]Credential{}
+
+	// Flatten the file into tokens, dropping macro definitions which run from `macdef` until the next blank line
+	var tokens []string
+	inMacro := false
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if inMacro {
+			inMacro = len(fields) > 0
+			continue
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			if field == "macdef" {
+				inMacro = true
+				break
+			}
+			tokens = append(tokens, field)
+		}
+	}
+
+	var (
+		current *Credential
+		name    string
+	)
+	save := func() {
+		if current != nil {
+			if _, exists := machines[name]; !exists {
+				machines[name] = *current
+			}
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		value := ""
+		if i+1 < len(tokens) {
+			value = tokens[i+1]
+		}
+
+		switch tokens[i] {
+		case "machine":
+			save()
+			name = strings.ToLower(value)
+			current = &Credential{Type: TypeBasic}
+			i++
+		case "default":
+			save()
+			name = ""
+			current = &Credential{Type: TypeBasic}
+		case "login":
+			if current != nil {
+				current.Username = value
+			}
+			i++
+		case "password":
+			if current != nil {
+				current.Password = value
+			}
+			i++
+		case "account":
+			i++
+		}
+	}
+	save()
+
+	return machines
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDir returns component-generator inside $XDG_CACHE_HOME, or else the user's cache directory.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "component-generator"), nil
+}
+
+// Cache stores fetched content once under its sha256 digest, with keys pointing at the digest they last fetched.
+type Cache struct {
+	dir string
+}
+
+// Entry is the content a key refers to, along with the name and identity it resolved to when it was fetched.
+type Entry struct {
+	Key      string `json:"key"`
+	Digest   string `json:"digest"`
+	Name     string `json:"name,omitempty"`
+	Resolved string `json:"resolved,omitempty"`
+	Content  []byte `json:"-"`
+}
+
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Get returns the entry stored for key. The boolean is false when the key has not been cached.
+func (c *Cache) Get(key string) (Entry, bool, error) {
+	var entry Entry
+
+	rawEntry, err := os.ReadFile(c.keyPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return entry, false, nil
+	} else if err != nil {
+		return entry, false, err
+	}
+	if err := json.Unmarshal(rawEntry, &entry); err != nil {
+		return entry, false, fmt.Errorf("corrupt cache entry for %s: %w", key, err)
+	}
+
+	content, ok, err := c.content(entry.Digest)
+	if err != nil || !ok {
+		return entry, ok, err
+	}
+	entry.Content = content
+	return entry, true, nil
+}
+
+// content returns the content stored under a `sha256:<hex>` digest, if cached.
+func (c *Cache) content(digest string) ([]byte, bool, error) {
+	encoded, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok {
+		return nil, false, nil
+	}
+	encoded = strings.ToLower(encoded)
+
+	content, err := os.ReadFile(filepath.Join(c.dir, "blobs", "sha256", filepath.Base(encoded)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	// Never serve content that has been modified on disk
+	if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != encoded {
+		return nil, false, nil
+	}
+	return content, true, nil
+}
+
+// Put stores the content of an entry and points its key at it.
+func (c *Cache) Put(entry Entry) error {
+	sum := sha256.Sum256(entry.Content)
+	encoded := hex.EncodeToString(sum[:])
+
+	if err := writeFileAtomic(filepath.Join(c.dir, "blobs", "sha256", encoded), entry.Content); err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+
+	entry.Digest = "sha256:" + encoded
+	rawEntry, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.keyPath(entry.Key), rawEntry); err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	return nil
+}
+
+// keyPath returns the path of the index file for a key. Keys are hashed as they are arbitrary strings such as URLs.
+func (c *Cache) keyPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, "keys", hex.EncodeToString(sum[:])+".json")
+}
+
+// writeFileAtomic writes through a temporary file so that concurrent readers never see a partial file.
+func writeFileAtomic(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
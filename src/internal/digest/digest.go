@@ -0,0 +1,53 @@
+// Package digest verifies content against digests of the form <algorithm>:<hex>.
+package digest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// algorithms maps the supported digest prefixes to their hash implementations.
+var algorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Validate checks that a digest is of the form `<algorithm>:<hex>` with a supported algorithm.
+func Validate(digest string) error {
+	_, _, err := parse(digest)
+	return err
+}
+
+// Verify checks that content matches an expected digest of the form `<algorithm>:<hex>`, e.g. `sha256:2c26b4...`.
+func Verify(content []byte, expected string) error {
+	newHash, want, err := parse(expected)
+	if err != nil {
+		return err
+	}
+
+	h := newHash()
+	h.Write(content)
+	actual := hex.EncodeToString(h.Sum(nil))
+
+	if !strings.EqualFold(actual, want) {
+		algorithm, _, _ := strings.Cut(expected, ":")
+		return fmt.Errorf("digest mismatch: expected %s:%s, got %s:%s", algorithm, want, algorithm, actual)
+	}
+	return nil
+}
+
+func parse(digest string) (func() hash.Hash, string, error) {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	if !ok {
+		return nil, "", fmt.Errorf("digest %q must be of the form <algorithm>:<hex>", digest)
+	}
+	newHash, ok := algorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return nil, "", fmt.Errorf("digest %q uses an unsupported algorithm - expected one of sha256, sha512", digest)
+	}
+	return newHash, encoded, nil
+}
@@ -0,0 +1,23 @@
+package digest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("component-definition")
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	require.NoError(t, Verify(content, digest))
+	require.NoError(t, Validate(digest))
+	require.ErrorContains(t, Verify([]byte("tampered"), digest), "digest mismatch")
+	require.ErrorContains(t, Verify(content, "md5:abc"), "unsupported algorithm")
+	require.ErrorContains(t, Validate(hex.EncodeToString(sum[:])), "must be of the form")
+}
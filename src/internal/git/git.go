@@ -0,0 +1,158 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/defenseunicorns/component-generator/src/internal/archive"
+)
+
+// FetchFile shallowly fetches `ref` of `repo` and returns the file at `filePath` along with the commit SHA.
+func FetchFile(repo, ref, filePath, authorization string) ([]byte, string, error) {
+	dir, err := os.MkdirTemp("", "component-generator-git-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary git directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := run(dir, nil, "init", "--quiet", "--bare"); err != nil {
+		return nil, "", err
+	}
+	if _, err := run(dir, extraHeader(repo, authorization), "fetch", "--quiet", "--depth", "1", "--no-tags", repo, ref); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s@%s: %w", repo, ref, err)
+	}
+
+	commit, err := run(dir, nil, "rev-parse", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return nil, "", err
+	}
+	contents, err := run(dir, nil, "show", "FETCH_HEAD:"+archive.CleanPath(filePath))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s from %s@%s: %w", filePath, repo, ref, err)
+	}
+	return contents, strings.TrimSpace(string(commit)), nil
+}
+
+// operatorPattern matches refs starting with a comparison operator, which can only be semver constraints.
+var operatorPattern = regexp.MustCompile(`^[~^<>=!]`)
+
+// rangePattern matches the wildcards, ranges and list separators that set a constraint apart from a literal version.
+var rangePattern = regexp.MustCompile(`[\s,|*]|(^|\.)[xX](\.|$)`)
+
+// IsConstraint reports whether a ref, such as `~1.60.0` or `1.x`, parses as a semver constraint.
+func IsConstraint(ref string) bool {
+	if operatorPattern.MatchString(ref) {
+		return true
+	}
+	if !rangePattern.MatchString(ref) {
+		return false
+	}
+	_, err := semver.NewConstraint(ref)
+	return err == nil
+}
+
+// ResolveConstraint returns the highest semver tag of `repo` satisfying `constraint`, preferring releases to
+// pre-releases. A constraint without an operator that names an existing ref, such as a `1.x` branch, is kept as is.
+func ResolveConstraint(repo, constraint, authorization string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	output, err := run("", extraHeader(repo, authorization), "ls-remote", "--heads", "--tags", "--refs", repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs of %s: %w", repo, err)
+	}
+	lines := strings.Split(string(output), "\n")
+
+	if !operatorPattern.MatchString(constraint) {
+		for _, line := range lines {
+			if _, ref, ok := strings.Cut(line, "\t"); ok && (ref == "refs/heads/"+constraint || ref == "refs/tags/"+constraint) {
+				return constraint, nil
+			}
+		}
+	}
+
+	// Index 0 holds the best tag matching the constraint, index 1 the best pre-release matching by its release version
+	var (
+		best    [2]*semver.Version
+		bestTag [2]string
+	)
+	for _, line := range lines {
+		_, ref, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		tag := strings.TrimPrefix(ref, "refs/tags/")
+		version, err := semver.NewVersion(tag)
+		if err != nil {
+			continue
+		}
+		i := 0
+		if !c.Check(version) {
+			release, err := version.SetPrerelease("")
+			if version.Prerelease() == "" || err != nil || !c.Check(&release) {
+				continue
+			}
+			i = 1
+		}
+		if best[i] == nil || version.GreaterThan(best[i]) {
+			best[i], bestTag[i] = version, tag
+		}
+	}
+
+	for i := range best {
+		if best[i] != nil {
+			return bestTag[i], nil
+		}
+	}
+	return "", fmt.Errorf("no tag of %s matches the version constraint %q", repo, constraint)
+}
+
+// extraHeader returns the environment that makes git send `authorization` only to the scheme and host of `repo`,
+// numbered after any config already passed through GIT_CONFIG_COUNT.
+func extraHeader(repo, authorization string) []string {
+	if authorization == "" {
+		return nil
+	}
+	remote, err := url.Parse(repo)
+	if err != nil || (remote.Scheme != "https" && remote.Scheme != "http") || remote.Host == "" {
+		return nil
+	}
+	count, err := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	if err != nil || count < 0 {
+		count = 0
+	}
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s://%s/.extraHeader", count, remote.Scheme, remote.Host),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: %s", count, authorization),
+	}
+}
+
+// run executes git inside `dir` with `env` added to its environment and returns its standard output.
+func run(dir string, env []string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Never block waiting on credentials that will not be supplied
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,173 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newBareRepo creates a bare repository with the files committed on `main`, tagged `v1.0.0` and any `tags`.
+func newBareRepo(t *testing.T, files map[string]string, tags ...string) string {
+	t.Helper()
+
+	work := t.TempDir()
+	bare := t.TempDir()
+
+	gitCmd := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	gitCmd(work, "init", "--quiet", "--initial-branch", "main")
+	for name, contents := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(work, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(work, name), []byte(contents), 0644))
+	}
+	gitCmd(work, "add", "-A")
+	gitCmd(work, "commit", "--quiet", "-m", "initial")
+	for _, tag := range append([]string{"v1.0.0"}, tags...) {
+		gitCmd(work, "tag", tag)
+	}
+	gitCmd(bare, "clone", "--quiet", "--bare", work, ".")
+
+	return bare
+}
+
+func TestFetchFile(t *testing.T) {
+	t.Parallel()
+
+	repo := newBareRepo(t, map[string]string{
+		"oscal-component.yaml":        "root",
+		"nested/oscal-component.yaml": "nested",
+	})
+
+	testCases := []struct {
+		name     string
+		repo     string
+		ref      string
+		path     string
+		expected string
+	}{
+		{name: "local path", repo: repo, ref: "v1.0.0", path: "oscal-component.yaml", expected: "root"},
+		{name: "file URL", repo: "file://" + repo, ref: "v1.0.0", path: "oscal-component.yaml", expected: "root"},
+		{name: "dot prefixed path", repo: repo, ref: "v1.0.0", path: "./nested/oscal-component.yaml", expected: "nested"},
+		{name: "leading slash", repo: repo, ref: "v1.0.0", path: "/nested/oscal-component.yaml", expected: "nested"},
+	}
+
+	for _, testCase := range testCases {
+		contents, commit, err := FetchFile(testCase.repo, testCase.ref, testCase.path, "")
+		require.NoError(t, err, testCase.name)
+		require.Equal(t, testCase.expected, string(contents), testCase.name)
+		require.Len(t, commit, 40, testCase.name)
+	}
+}
+
+func TestFetchFileErrors(t *testing.T) {
+	t.Parallel()
+
+	repo := newBareRepo(t, map[string]string{"oscal-component.yaml": "root"})
+
+	_, _, err := FetchFile(repo, "v9.9.9", "oscal-component.yaml", "")
+	require.ErrorContains(t, err, "failed to fetch")
+
+	_, _, err = FetchFile(repo, "v1.0.0", "missing.yaml", "")
+	require.ErrorContains(t, err, "failed to read missing.yaml")
+}
+
+func TestFetchFileRefKinds(t *testing.T) {
+	t.Parallel()
+
+	repo := newBareRepo(t, map[string]string{"oscal-component.yaml": "root"})
+
+	_, commit, err := FetchFile(repo, "v1.0.0", "oscal-component.yaml", "")
+	require.NoError(t, err)
+
+	for _, ref := range []string{"main", commit} {
+		contents, resolved, err := FetchFile(repo, ref, "oscal-component.yaml", "")
+		require.NoError(t, err, ref)
+		require.Equal(t, "root", string(contents), ref)
+		require.Equal(t, commit, resolved, ref)
+	}
+}
+
+func TestExtraHeader(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "")
+
+	require.Equal(t, []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://repo1.dso.mil/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Bearer token",
+	}, extraHeader("https://robot@repo1.dso.mil/big-bang/apps/core/kiali.git", "Bearer token"))
+	require.Nil(t, extraHeader("https://repo1.dso.mil/big-bang/apps/core/kiali.git", ""))
+	require.Nil(t, extraHeader("/srv/repos/kiali.git", "Bearer token"))
+
+	// Config already passed through the environment is kept
+	t.Setenv("GIT_CONFIG_COUNT", "2")
+	require.Equal(t, []string{
+		"GIT_CONFIG_COUNT=3",
+		"GIT_CONFIG_KEY_2=http.https://repo1.dso.mil/.extraHeader",
+		"GIT_CONFIG_VALUE_2=Authorization: Bearer token",
+	}, extraHeader("https://repo1.dso.mil/big-bang/apps/core/kiali.git", "Bearer token"))
+}
+
+func TestIsConstraint(t *testing.T) {
+	t.Parallel()
+
+	for _, ref := range []string{"~1.60.0", "^1.2", ">= 1.0, < 2.0", "1.60.x", "1.*", "1.2 || 1.3"} {
+		require.True(t, IsConstraint(ref), ref)
+	}
+	for _, ref := range []string{"1.60.0-bb.2", "v0.0.3", "main", "feature/x-ray", "release-1.x", "0123456789abcdef0123456789abcdef01234567"} {
+		require.False(t, IsConstraint(ref), ref)
+	}
+}
+
+func TestResolveConstraint(t *testing.T) {
+	t.Parallel()
+
+	repo := newBareRepo(t, map[string]string{"oscal-component.yaml": "root"}, "1.60.0-bb.2", "1.60.3-bb.0", "v1.60.1", "v1.60.2", "v1.61.0", "not-a-version", "2.x")
+
+	testCases := []struct {
+		constraint string
+		expected   string
+		err        string
+	}{
+		{constraint: "~1.60.0", expected: "v1.60.2"},
+		{constraint: "^1.0.0", expected: "v1.61.0"},
+		{constraint: "~1.60.0-0", expected: "1.60.3-bb.0"},
+		{constraint: "1.60.x", expected: "v1.60.2"},
+		// A ref that exists is used as is, even when it reads as a constraint
+		{constraint: "2.x", expected: "2.x"},
+		{constraint: "~2.0.0", err: `no tag of ` + repo + ` matches the version constraint "~2.0.0"`},
+		{constraint: "~banana", err: "invalid version constraint"},
+	}
+
+	for _, testCase := range testCases {
+		tag, err := ResolveConstraint(repo, testCase.constraint, "")
+		if testCase.err != "" {
+			require.ErrorContains(t, err, testCase.err, testCase.constraint)
+			continue
+		}
+		require.NoError(t, err, testCase.constraint)
+		require.Equal(t, testCase.expected, tag, testCase.constraint)
+	}
+
+	// Projects such as Big Bang only tag pre-releases, which match by their release version
+	bigBang := newBareRepo(t, map[string]string{"oscal-component.yaml": "root"}, "1.59.0-bb.4", "1.60.0-bb.1", "1.60.0-bb.2", "1.61.0-bb.0")
+	for constraint, expected := range map[string]string{"~1.60.0": "1.60.0-bb.2", "1.60.x": "1.60.0-bb.2", "^1.59.0": "1.61.0-bb.0", "~1.59.0": "1.59.0-bb.4"} {
+		tag, err := ResolveConstraint(bigBang, constraint, "")
+		require.NoError(t, err, constraint)
+		require.Equal(t, expected, tag, constraint)
+	}
+	_, err := ResolveConstraint(bigBang, "~1.62.0", "")
+	require.ErrorContains(t, err, `no tag of `+bigBang+` matches the version constraint "~1.62.0"`)
+}
@@ -0,0 +1,144 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/archive"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFile is the file read from a chart when no path is specified.
+const DefaultFile = "oscal-component.yaml"
+
+// Chart is the subset of Chart.yaml needed to identify a chart.
+type Chart struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// ReadChartFile reads a file from a chart directory or packaged chart (.tgz), along with the chart's metadata.
+func ReadChartFile(chartPath, filePath string) ([]byte, Chart, error) {
+	info, err := os.Stat(chartPath)
+	if err != nil {
+		return nil, Chart{}, err
+	}
+	if info.IsDir() {
+		return readChartDir(chartPath, filePath)
+	}
+
+	f, err := os.Open(chartPath)
+	if err != nil {
+		return nil, Chart{}, err
+	}
+	defer f.Close()
+	return ReadPackagedChartFile(f, filePath)
+}
+
+// ReadPackagedChartFile reads a file from a packaged chart, ignoring the charts of its dependencies.
+func ReadPackagedChartFile(r io.Reader, filePath string) ([]byte, Chart, error) {
+	filePath = chartFilePath(filePath)
+
+	var chartFile, content []byte
+	found := false
+	err := archive.Walk(r, archive.IsArchive, func(name string, r io.Reader) error {
+		_, rel, ok := strings.Cut(name, "/")
+		if !ok {
+			return nil
+		}
+		switch rel {
+		case "Chart.yaml":
+			data, err := io.ReadAll(r)
+			chartFile = data
+			return err
+		case filePath:
+			data, err := io.ReadAll(r)
+			content, found = data, true
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, Chart{}, err
+	}
+
+	chart, err := parseChart(chartFile)
+	if err != nil {
+		return nil, chart, err
+	}
+	if !found {
+		return nil, chart, fmt.Errorf("chart %s %s does not contain %s", chart.Name, chart.Version, filePath)
+	}
+	return content, chart, nil
+}
+
+func readChartDir(dir, filePath string) ([]byte, Chart, error) {
+	chartFile, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return nil, Chart{}, fmt.Errorf("%s is not a chart: %w", dir, err)
+	}
+	chart, err := parseChart(chartFile)
+	if err != nil {
+		return nil, chart, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(chartFilePath(filePath))))
+	if err != nil {
+		return nil, chart, fmt.Errorf("chart %s %s: %w", chart.Name, chart.Version, err)
+	}
+	return content, chart, nil
+}
+
+func parseChart(chartFile []byte) (Chart, error) {
+	var chart Chart
+
+	if chartFile == nil {
+		return chart, fmt.Errorf("chart is missing Chart.yaml")
+	}
+	if err := yaml.Unmarshal(chartFile, &chart); err != nil {
+		return chart, fmt.Errorf("failed to parse Chart.yaml: %w", err)
+	}
+	return chart, nil
+}
+
+// IndexEntry is a single chart version listed in a chart repository index.
+type IndexEntry struct {
+	Version string   `yaml:"version"`
+	URLs    []string `yaml:"urls"`
+	Digest  string   `yaml:"digest"`
+}
+
+// FindInIndex looks up a chart version in the content of a chart repository's index.yaml.
+func FindInIndex(index []byte, name, version string) (IndexEntry, error) {
+	var parsed struct {
+		Entries map[string][]IndexEntry `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(index, &parsed); err != nil {
+		return IndexEntry{}, fmt.Errorf("failed to parse chart repository index: %w", err)
+	}
+
+	versions, ok := parsed.Entries[name]
+	if !ok {
+		return IndexEntry{}, fmt.Errorf("chart %s is not in the repository index", name)
+	}
+	for _, entry := range versions {
+		if entry.Version == version {
+			if len(entry.URLs) == 0 {
+				return entry, fmt.Errorf("chart %s %s has no download URL in the repository index", name, version)
+			}
+			return entry, nil
+		}
+	}
+	return IndexEntry{}, fmt.Errorf("chart %s has no version %s in the repository index", name, version)
+}
+
+// chartFilePath makes a path relative to the chart root, defaulting to DefaultFile.
+func chartFilePath(filePath string) string {
+	if filePath == "" {
+		return DefaultFile
+	}
+	return archive.CleanPath(filePath)
+}
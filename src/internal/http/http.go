@@ -3,44 +3,164 @@ package http
 import (
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
-	"strings"
+	"strconv"
 	"time"
 )
 
-// FetchFromHTTPResource downloads the file located at `uri` and returns the response code, the response body, and any
-// error.
-func FetchFromHTTPResource(uri *url.URL) (int, []byte, error) {
-	c := http.Client{Timeout: 10 * time.Second}
-	resp, err := c.Get(uri.String())
+const (
+	// defaultBackoff is the delay before the first retry of a Client that sets no Backoff
+	defaultBackoff = 500 * time.Millisecond
+	// maxBackoff caps the delay between attempts, including delays requested with Retry-After
+	maxBackoff = 2 * time.Minute
+)
+
+// Client performs GET requests, retrying network errors, 5xx and 429 responses with exponential backoff and jitter.
+type Client struct {
+	// Timeout limits each attempt
+	Timeout time.Duration
+	// Retries is the number of attempts made after the first one fails
+	Retries int
+	// Backoff is the delay before the first retry, doubled for every retry after it, defaulting to 500ms when zero
+	Backoff time.Duration
+}
+
+// DefaultClient is used by the package level functions.
+var DefaultClient = Client{Timeout: 10 * time.Second, Retries: 3, Backoff: defaultBackoff}
+
+// Response is the status, headers and fully read body of an HTTP response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// StatusError is returned when a request still received a retryable status once every attempt had been used.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Attempts   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s failed after %d attempt(s): %d %s", e.URL, e.Attempts, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Get performs a GET request, returning responses that are not retried for the caller to interpret.
+func (c Client) Get(uri *url.URL, header http.Header) (Response, error) {
+	client := http.Client{Timeout: c.Timeout, CheckRedirect: dropCredentialsOnRedirect}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(&client, uri, header)
+		retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+		if !retryable {
+			return resp, nil
+		}
+		if attempt >= c.Retries {
+			if err != nil {
+				return Response{}, fmt.Errorf("GET %s failed after %d attempt(s): %w", uri, attempt+1, err)
+			}
+			return resp, &StatusError{URL: uri.String(), StatusCode: resp.StatusCode, Attempts: attempt + 1}
+		}
+
+		time.Sleep(c.delay(attempt, resp.Header))
+	}
+}
+
+// FetchFromHTTPResource downloads the file located at `uri` with any additional `header` values and returns the
+// response code, the response body, and any error.
+func (c Client) FetchFromHTTPResource(uri *url.URL, header http.Header) (int, []byte, error) {
+	resp, err := c.Get(uri, header)
 	if err != nil {
-		return 0, nil, err
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, resp.Body, nil
+}
+
+// Get performs a GET request with the DefaultClient.
+func Get(uri *url.URL, header http.Header) (Response, error) {
+	return DefaultClient.Get(uri, header)
+}
+
+// FetchFromHTTPResource downloads a file with the DefaultClient.
+func FetchFromHTTPResource(uri *url.URL, header http.Header) (int, []byte, error) {
+	return DefaultClient.FetchFromHTTPResource(uri, header)
+}
+
+// do performs a single attempt.
+func (c Client) do(client *http.Client, uri *url.URL, header http.Header) (Response, error) {
+	req, err := http.NewRequest(http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return Response{}, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, nil, fmt.Errorf("cannot read response body %v", err)
+		return Response{}, fmt.Errorf("cannot read response body %v", err)
 	}
-	return resp.StatusCode, body, nil
+	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
 }
 
-func ConstructURL(repo, tag, path string) (*url.URL, error) {
-	var rawURL string
+// delay returns how long to wait before the next attempt, honoring Retry-After over the jittered backoff.
+func (c Client) delay(attempt int, header http.Header) time.Duration {
+	if retryAfter, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		if retryAfter > maxBackoff {
+			return maxBackoff
+		}
+		return retryAfter
+	}
 
-	switch {
-	case strings.Contains(repo, ".git"):
-		repo = strings.TrimSuffix(repo, ".git")
-		rawURL = fmt.Sprintf("%s/-/raw/%s/%s", repo, tag, path)
-	case strings.Contains(repo, "github.com"):
-		repo = strings.Replace(repo, "github.com", "raw.githubusercontent.com", 1)
-		rawURL = fmt.Sprintf("%s/%s/%s", repo, tag, path)
+	base := c.Backoff
+	if base <= 0 {
+		base = defaultBackoff
 	}
+	// A shift that overflows turns negative, which is capped like any other delay past the maximum
+	backoff := base << attempt
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
 
-	uri, err := url.Parse(rawURL)
-	if err != nil {
-		return uri, fmt.Errorf("failed to parse git URL: %w", err)
+// parseRetryAfter interprets a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait, true
+		}
+		return 0, true
 	}
+	return 0, false
+}
 
-	return uri, nil
-}
\ No newline at end of file
+// dropCredentialsOnRedirect removes credential headers, including PRIVATE-TOKEN, on redirects to another host.
+func dropCredentialsOnRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del("Authorization")
+		req.Header.Del("Private-Token")
+	}
+	return nil
+}
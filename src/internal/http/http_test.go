@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer returns a server that responds with each of `statuses` in turn and then with 200 OK.
+func newFlakyServer(t *testing.T, statuses ...int) (*url.URL, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n <= len(statuses) {
+			if statuses[n-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	uri, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return uri, &requests
+}
+
+func TestClientRetries(t *testing.T) {
+	t.Parallel()
+
+	client := Client{Timeout: time.Second, Retries: 3, Backoff: time.Millisecond}
+
+	uri, requests := newFlakyServer(t, http.StatusBadGateway, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	code, body, err := client.FetchFromHTTPResource(uri, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", string(body))
+	require.Equal(t, int32(4), atomic.LoadInt32(requests))
+
+	// Statuses that are not transient are returned to the caller straight away
+	uri, requests = newFlakyServer(t, http.StatusNotFound)
+	code, _, err = client.FetchFromHTTPResource(uri, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, code)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	uri, requests = newFlakyServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	code, _, err = client.FetchFromHTTPResource(uri, nil)
+	require.EqualError(t, err, "GET "+uri.String()+" failed after 4 attempt(s): 502 Bad Gateway")
+	require.Equal(t, http.StatusBadGateway, code)
+	require.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestDelay(t *testing.T) {
+	t.Parallel()
+
+	// A zero-value client backs off from the default delay, not the maximum
+	delay := Client{}.delay(0, http.Header{})
+	require.GreaterOrEqual(t, delay, defaultBackoff/2)
+	require.LessOrEqual(t, delay, defaultBackoff)
+
+	delay = Client{Backoff: time.Second}.delay(2, http.Header{})
+	require.GreaterOrEqual(t, delay, 2*time.Second)
+	require.LessOrEqual(t, delay, 4*time.Second)
+
+	require.LessOrEqual(t, Client{Backoff: time.Second}.delay(62, http.Header{}), maxBackoff)
+	require.Equal(t, 7*time.Second, Client{}.delay(0, http.Header{"Retry-After": []string{"7"}}))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	delay, ok := parseRetryAfter("7")
+	require.True(t, ok)
+	require.Equal(t, 7*time.Second, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	require.True(t, ok)
+	require.Zero(t, delay)
+
+	_, ok = parseRetryAfter("soon")
+	require.False(t, ok)
+}
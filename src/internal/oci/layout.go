@@ -0,0 +1,84 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/digest"
+)
+
+// refNameAnnotation is the index annotation an OCI layout uses to name (tag) a manifest.
+const refNameAnnotation = "org.opencontainers.image.ref.name"
+
+// PullFromLayout retrieves the layer with the given media type from the manifest of layout `dir` that `ref` names
+// by digest or ref.name annotation, or its only manifest when `ref` is empty.
+func PullFromLayout(dir, ref, mediaType string) ([]byte, string, error) {
+	var layout struct {
+		ImageLayoutVersion string `json:"imageLayoutVersion"`
+	}
+	if err := readJSON(filepath.Join(dir, "oci-layout"), &layout); err != nil {
+		return nil, "", fmt.Errorf("%s is not an OCI layout: %w", dir, err)
+	}
+
+	var index manifest
+	if err := readJSON(filepath.Join(dir, "index.json"), &index); err != nil {
+		return nil, "", err
+	}
+
+	var matches []Descriptor
+	for _, desc := range index.Manifests {
+		switch {
+		case ref == "", strings.Contains(ref, ":") && desc.Digest == ref, desc.Annotations[refNameAnnotation] == ref:
+			matches = append(matches, desc)
+		}
+	}
+	if len(matches) != 1 {
+		return nil, "", fmt.Errorf("expected exactly one manifest matching %q in %s, found %d", ref, dir, len(matches))
+	}
+	desc := matches[0]
+
+	manifestContent, err := readBlob(dir, desc.Digest)
+	if err != nil {
+		return nil, "", err
+	}
+	layer, err := findLayer(manifestContent, mediaType)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s@%s: %w", dir, ref, err)
+	}
+	content, err := readBlob(dir, layer.Digest)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, desc.Digest, nil
+}
+
+// readBlob reads the blob with the given digest from a layout and verifies its content.
+func readBlob(dir, blobDigest string) ([]byte, error) {
+	algorithm, encoded, ok := strings.Cut(blobDigest, ":")
+	if !ok || strings.ContainsAny(encoded, `/\.`) {
+		return nil, fmt.Errorf("invalid digest %q", blobDigest)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "blobs", algorithm, encoded))
+	if err != nil {
+		return nil, err
+	}
+	if err := digest.Verify(content, blobDigest); err != nil {
+		return nil, fmt.Errorf("blob %s: %w", blobDigest, err)
+	}
+	return content, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/digest"
+)
+
+// DefaultMediaType is the layer media type that holds a component-definition when none is specified.
+const DefaultMediaType = "application/vnd.oscal.component-definition+yaml"
+
+const (
+	mediaTypeImageManifest  = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeImageIndex     = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// Descriptor describes content stored in a registry or OCI layout.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// manifest covers the fields shared by image manifests and image indexes that are needed to locate a layer.
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// Reference is a parsed image reference - REGISTRY/REPOSITORY[:TAG][@DIGEST].
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference such as `registry.example.com/org/comp-def:1.2.3`, defaulting to `latest`.
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+
+	name := s
+	if at := strings.Index(name, "@"); at >= 0 {
+		ref.Digest = name[at+1:]
+		name = name[:at]
+		if err := digest.Validate(ref.Digest); err != nil {
+			return ref, fmt.Errorf("invalid reference %q: %w", s, err)
+		}
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		ref.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+
+	registry, repository, ok := strings.Cut(name, "/")
+	if !ok || repository == "" || !(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		return ref, fmt.Errorf("invalid reference %q: must be of the form REGISTRY/REPOSITORY[:TAG][@DIGEST]", s)
+	}
+	ref.Registry = registry
+	ref.Repository = repository
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// findLayer parses a manifest and returns the descriptor of its single layer with the given media type.
+func findLayer(content []byte, mediaType string) (Descriptor, error) {
+	var m manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return Descriptor{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.MediaType == mediaTypeImageIndex || m.MediaType == mediaTypeDockerList || len(m.Manifests) > 0 {
+		return Descriptor{}, fmt.Errorf("reference resolves to an image index - reference a single manifest by digest instead")
+	}
+
+	var found []Descriptor
+	for _, layer := range m.Layers {
+		if layer.MediaType == mediaType {
+			found = append(found, layer)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Descriptor{}, fmt.Errorf("no layer with media type %s", mediaType)
+	case 1:
+		return found[0], nil
+	default:
+		return Descriptor{}, fmt.Errorf("found %d layers with media type %s, expected 1", len(found), mediaType)
+	}
+}
@@ -0,0 +1,168 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	internalhttp "github.com/defenseunicorns/component-generator/src/internal/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	testCases := []struct {
+		input    string
+		expected Reference
+		err      string
+	}{
+		{input: "registry.example.com/org/comp-def:1.2.3", expected: Reference{Registry: "registry.example.com", Repository: "org/comp-def", Tag: "1.2.3"}},
+		{input: "localhost:5000/comp-def", expected: Reference{Registry: "localhost:5000", Repository: "comp-def", Tag: "latest"}},
+		{input: "localhost/comp-def@" + digest, expected: Reference{Registry: "localhost", Repository: "comp-def", Digest: digest}},
+		{input: "registry.example.com/comp-def:1.2.3@" + digest, expected: Reference{Registry: "registry.example.com", Repository: "comp-def", Tag: "1.2.3", Digest: digest}},
+		{input: "org/comp-def:1.2.3", err: "must be of the form"},
+		{input: "registry.example.com/comp-def@md5:abc", err: "unsupported algorithm"},
+	}
+
+	for _, testCase := range testCases {
+		ref, err := ParseReference(testCase.input)
+		if testCase.err != "" {
+			require.ErrorContains(t, err, testCase.err, testCase.input)
+			continue
+		}
+		require.NoError(t, err, testCase.input)
+		require.Equal(t, testCase.expected, ref, testCase.input)
+	}
+}
+
+// artifact is a single layer artifact and its manifest, stored by digest.
+type artifact struct {
+	blobs          map[string][]byte
+	manifestDigest string
+}
+
+func newArtifact(t *testing.T, layers map[string]string) artifact {
+	t.Helper()
+
+	a := artifact{blobs: map[string][]byte{}}
+	add := func(content []byte) Descriptor {
+		sum := sha256.Sum256(content)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		a.blobs[digest] = content
+		return Descriptor{Digest: digest, Size: int64(len(content))}
+	}
+
+	m := manifest{MediaType: mediaTypeImageManifest}
+	for mediaType, content := range layers {
+		desc := add([]byte(content))
+		desc.MediaType = mediaType
+		m.Layers = append(m.Layers, desc)
+	}
+	content, err := json.Marshal(m)
+	require.NoError(t, err)
+	a.manifestDigest = add(content).Digest
+
+	return a
+}
+
+func TestPull(t *testing.T) {
+	t.Parallel()
+
+	a := newArtifact(t, map[string]string{
+		DefaultMediaType:        "component-definition",
+		"application/vnd.other": "other",
+	})
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:org/comp-def:pull,push" || r.URL.Query().Get("service") != "test" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"anonymous"}`))
+			return
+		case r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test",scope="repository:org/comp-def:pull,push"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/v2/org/comp-def/")
+		if path == "manifests/1.2.3" {
+			path = "manifests/" + a.manifestDigest
+		}
+		_, digest, _ := strings.Cut(path, "/")
+		content, ok := a.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	content, manifestDigest, err := Pull(Reference{Registry: registry, Repository: "org/comp-def", Tag: "1.2.3"}, DefaultMediaType, PullOptions{HTTP: internalhttp.DefaultClient})
+	require.NoError(t, err)
+	require.Equal(t, "component-definition", string(content))
+	require.Equal(t, a.manifestDigest, manifestDigest)
+
+	_, _, err = Pull(Reference{Registry: registry, Repository: "org/comp-def", Digest: a.manifestDigest}, "application/vnd.missing", PullOptions{HTTP: internalhttp.DefaultClient})
+	require.ErrorContains(t, err, "no layer with media type application/vnd.missing")
+
+	_, _, err = Pull(Reference{Registry: registry, Repository: "org/comp-def", Tag: "1.2.3", Digest: "sha256:" + strings.Repeat("0", 64)}, DefaultMediaType, PullOptions{HTTP: internalhttp.DefaultClient})
+	require.Error(t, err)
+}
+
+func TestParseAuthParams(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:org/x:pull,push",
+		"error":   `say "hi"`,
+	}, parseAuthParams(`realm="https://auth.example.com/token", service=registry.example.com,Scope="repository:org/x:pull,push",error="say \"hi\""`))
+}
+
+func TestPullFromLayout(t *testing.T) {
+	t.Parallel()
+
+	a := newArtifact(t, map[string]string{DefaultMediaType: "component-definition"})
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755))
+	for digest, content := range a.blobs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:")), content, 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644))
+	index, err := json.Marshal(manifest{Manifests: []Descriptor{{
+		MediaType:   mediaTypeImageManifest,
+		Digest:      a.manifestDigest,
+		Annotations: map[string]string{refNameAnnotation: "1.2.3"},
+	}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.json"), index, 0644))
+
+	for _, ref := range []string{"1.2.3", a.manifestDigest, ""} {
+		content, manifestDigest, err := PullFromLayout(dir, ref, DefaultMediaType)
+		require.NoError(t, err, ref)
+		require.Equal(t, "component-definition", string(content), ref)
+		require.Equal(t, a.manifestDigest, manifestDigest, ref)
+	}
+
+	_, _, err = PullFromLayout(dir, "9.9.9", DefaultMediaType)
+	require.ErrorContains(t, err, "expected exactly one manifest")
+}
@@ -0,0 +1,216 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/auth"
+	"github.com/defenseunicorns/component-generator/src/internal/digest"
+	internalhttp "github.com/defenseunicorns/component-generator/src/internal/http"
+)
+
+var manifestAccept = strings.Join([]string{mediaTypeImageManifest, mediaTypeDockerManifest, mediaTypeImageIndex, mediaTypeDockerList}, ", ")
+
+// Pull retrieves the layer with the given media type from an OCI registry, along with the digest of its manifest.
+func Pull(ref Reference, mediaType string, opts PullOptions) ([]byte, string, error) {
+	c := &client{ref: ref, cred: opts.Credential, http: opts.HTTP}
+
+	manifestRef := ref.Tag
+	if ref.Digest != "" {
+		manifestRef = ref.Digest
+	}
+	manifestContent, header, err := c.get("manifests/"+manifestRef, manifestAccept)
+	if err != nil {
+		return nil, "", err
+	}
+
+	manifestDigest := ref.Digest
+	if manifestDigest != "" {
+		if err := digest.Verify(manifestContent, manifestDigest); err != nil {
+			return nil, "", fmt.Errorf("manifest for %s: %w", ref, err)
+		}
+	} else if manifestDigest = header.Get("Docker-Content-Digest"); manifestDigest == "" || digest.Verify(manifestContent, manifestDigest) != nil {
+		sum := sha256.Sum256(manifestContent)
+		manifestDigest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	layer, err := findLayer(manifestContent, mediaType)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", ref, err)
+	}
+	content, _, err := c.get("blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, "", err
+	}
+	if err := digest.Verify(content, layer.Digest); err != nil {
+		return nil, "", fmt.Errorf("layer %s of %s: %w", layer.Digest, ref, err)
+	}
+
+	return content, manifestDigest, nil
+}
+
+// PullOptions controls how a registry is reached.
+type PullOptions struct {
+	// Credential is presented when the registry challenges for authentication. It may be nil for anonymous access
+	Credential *auth.Credential
+	HTTP       internalhttp.Client
+}
+
+// client performs requests against a single repository, answering the first authentication challenge it receives.
+type client struct {
+	ref  Reference
+	cred *auth.Credential
+	http internalhttp.Client
+	// authorization is the Authorization header value established by the challenge
+	authorization string
+}
+
+func (c *client) get(path, accept string) ([]byte, http.Header, error) {
+	uri, err := url.Parse(fmt.Sprintf("%s://%s/v2/%s/%s", scheme(c.ref.Registry), c.ref.Registry, c.ref.Repository, path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to construct registry URL: %w", err)
+	}
+
+	resp, err := c.http.Get(uri, c.header(accept))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		if c.authorization, err = c.authenticate(resp.Header.Get("WWW-Authenticate")); err != nil {
+			return nil, nil, fmt.Errorf("failed to authenticate to %s: %w", c.ref.Registry, err)
+		}
+		if resp, err = c.http.Get(uri, c.header(accept)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected response code when requesting %s: %v", uri, resp.StatusCode)
+	}
+	return resp.Body, resp.Header, nil
+}
+
+func (c *client) header(accept string) http.Header {
+	header := http.Header{}
+	if accept != "" {
+		header.Set("Accept", accept)
+	}
+	if c.authorization != "" {
+		header.Set("Authorization", c.authorization)
+	}
+	return header
+}
+
+// authenticate answers a Basic or Bearer challenge and returns the Authorization header value to retry with.
+func (c *client) authenticate(challenge string) (string, error) {
+	kind, params, _ := strings.Cut(challenge, " ")
+	switch {
+	case strings.EqualFold(kind, "Basic"):
+		if c.cred == nil {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+		return c.cred.BasicHeader(), nil
+	case strings.EqualFold(kind, "Bearer"):
+		token, err := c.fetchToken(params)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// fetchToken requests a token from the realm of a Bearer challenge with the given parameters.
+func (c *client) fetchToken(params string) (string, error) {
+	values := url.Values{}
+	var realm string
+	for key, value := range parseAuthParams(params) {
+		if key == "realm" {
+			realm = value
+		} else if key == "service" || key == "scope" {
+			values.Set(key, value)
+		}
+	}
+	if realm == "" {
+		return "", fmt.Errorf("bearer challenge has no realm")
+	}
+
+	uri, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token realm: %w", err)
+	}
+	uri.RawQuery = values.Encode()
+	header := http.Header{}
+	if c.cred != nil {
+		header.Set("Authorization", c.cred.BasicHeader())
+	}
+	resp, err := c.http.Get(uri, header)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response code when requesting token: %v", resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseAuthParams parses the auth-params of a challenge into lower-cased keys and unquoted values.
+func parseAuthParams(params string) map[string]string {
+	parsed := map[string]string{}
+	for rest := params; rest != ""; {
+		rest = strings.TrimLeft(rest, " \t,")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimLeft(value, " \t")
+
+		if strings.HasPrefix(value, `"`) {
+			var unquoted strings.Builder
+			i := 1
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				unquoted.WriteByte(value[i])
+			}
+			parsed[key] = unquoted.String()
+			rest = strings.TrimPrefix(value[i:], `"`)
+			continue
+		}
+		value, rest, _ = strings.Cut(value, ",")
+		parsed[key] = strings.TrimSpace(value)
+	}
+	return parsed
+}
+
+// scheme returns the URL scheme used to reach a registry - plain HTTP for the local machine, HTTPS otherwise.
+func scheme(registry string) string {
+	host := registry
+	if h, _, ok := strings.Cut(registry, ":"); ok && !strings.HasPrefix(registry, "[") {
+		host = h
+	}
+	switch {
+	case host == "localhost", strings.HasPrefix(host, "127."), strings.HasPrefix(registry, "[::1]"):
+		return "http"
+	default:
+		return "https"
+	}
+}
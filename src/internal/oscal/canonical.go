@@ -0,0 +1,161 @@
+package oscal
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+)
+
+// Canonicalize sorts the parts of a component-definition by stable keys and normalizes whitespace, so that documents
+// differing only in order or insignificant whitespace serialize identically.
+func Canonicalize(document *types.OscalComponentDocument) {
+	normalizeStrings(reflect.ValueOf(document).Elem())
+
+	definition := &document.ComponentDefinition
+	sort.SliceStable(definition.Components, func(i, j int) bool {
+		a, b := definition.Components[i], definition.Components[j]
+		return less(strings.ToLower(a.Title), strings.ToLower(b.Title), a.UUID, b.UUID)
+	})
+	for i := range definition.Components {
+		sortControlImplementations(definition.Components[i].ControlImplementations)
+	}
+	sort.SliceStable(definition.Capabilities, func(i, j int) bool {
+		a, b := definition.Capabilities[i], definition.Capabilities[j]
+		return less(strings.ToLower(a.Name), strings.ToLower(b.Name), a.UUID, b.UUID)
+	})
+	for i := range definition.Capabilities {
+		sortControlImplementations(definition.Capabilities[i].ControlImplementations)
+	}
+	sort.SliceStable(definition.BackMatter.Resources, func(i, j int) bool {
+		a, b := definition.BackMatter.Resources[i], definition.BackMatter.Resources[j]
+		return less(strings.ToLower(a.UUID), strings.ToLower(b.UUID), a.Title, b.Title)
+	})
+}
+
+func sortControlImplementations(implementations []types.ControlImplementation) {
+	sort.SliceStable(implementations, func(i, j int) bool {
+		a, b := implementations[i], implementations[j]
+		return less(a.Source, b.Source, a.UUID, b.UUID)
+	})
+	for i := range implementations {
+		requirements := implementations[i].ImplementedRequirements
+		sort.SliceStable(requirements, func(i, j int) bool {
+			a, b := requirements[i], requirements[j]
+			if c := CompareNatural(a.ControlId, b.ControlId); c != 0 {
+				return c < 0
+			}
+			return strings.ToLower(a.UUID) < strings.ToLower(b.UUID)
+		})
+		for k := range requirements {
+			statements := requirements[k].Statements
+			sort.SliceStable(statements, func(i, j int) bool {
+				a, b := statements[i], statements[j]
+				if c := CompareNatural(a.StatementId, b.StatementId); c != 0 {
+					return c < 0
+				}
+				return strings.ToLower(a.UUID) < strings.ToLower(b.UUID)
+			})
+		}
+	}
+}
+
+// less orders by a primary key, then by a secondary key compared case-insensitively.
+func less(a, b, secondA, secondB string) bool {
+	if a != b {
+		return a < b
+	}
+	return strings.ToLower(secondA) < strings.ToLower(secondB)
+}
+
+// CompareNatural compares identifiers case-insensitively, treating runs of digits as numbers - ac-2 before ac-10.
+func CompareNatural(a, b string) int {
+	for a != "" && b != "" {
+		runA, restA := splitRun(a)
+		runB, restB := splitRun(b)
+
+		digitsA, digitsB := unicode.IsDigit(rune(runA[0])), unicode.IsDigit(rune(runB[0]))
+		switch {
+		case digitsA && digitsB:
+			numA, numB := strings.TrimLeft(runA, "0"), strings.TrimLeft(runB, "0")
+			if len(numA) != len(numB) {
+				return len(numA) - len(numB)
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+		case digitsA != digitsB:
+			if digitsA {
+				return -1
+			}
+			return 1
+		default:
+			if c := strings.Compare(strings.ToLower(runA), strings.ToLower(runB)); c != 0 {
+				return c
+			}
+		}
+		a, b = restA, restB
+	}
+	return len(a) - len(b)
+}
+
+// splitRun splits off the leading run of digits, or of other characters, of a non-empty string.
+func splitRun(s string) (string, string) {
+	digits := unicode.IsDigit(rune(s[0]))
+	for i := 1; i < len(s); i++ {
+		if unicode.IsDigit(rune(s[i])) != digits {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+// normalizeStrings normalizes the whitespace of every string reachable from a value.
+func normalizeStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(normalizeWhitespace(v.String()))
+	case reflect.Ptr:
+		if !v.IsNil() {
+			normalizeStrings(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.Kind() == reflect.String && proseFields[v.Type().Field(i).Name] {
+				field.SetString(normalizeProse(field.String()))
+				continue
+			}
+			normalizeStrings(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			normalizeStrings(v.Index(i))
+		}
+	}
+}
+
+// proseFields names the fields that hold markup-multiline prose.
+var proseFields = map[string]bool{
+	"Description": true,
+	"Remarks":     true,
+}
+
+func normalizeWhitespace(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// normalizeProse only normalizes line endings and trims blank lines, as indentation and trailing spaces are
+// significant in markdown.
+func normalizeProse(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	blank := func(line string) bool { return strings.TrimSpace(line) == "" }
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
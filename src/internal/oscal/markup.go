@@ -0,0 +1,428 @@
+package oscal
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// markup holds OSCAL XML prose as the raw XML between its element's tags.
+type markup struct {
+	Inner string `xml:",innerxml"`
+}
+
+// markupNode is an element or, when it has no name, a run of text within markup.
+type markupNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []markupNode
+}
+
+// line converts markup-line prose to markdown.
+func (m *markup) line() string {
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(renderInline(parseMarkup(m.Inner)))
+}
+
+// multiline converts markup-multiline prose to markdown.
+func (m *markup) multiline() string {
+	if m == nil {
+		return ""
+	}
+	return renderProse(parseMarkup(m.Inner))
+}
+
+// renderProse converts blocks to markdown separated by blank lines.
+func renderProse(nodes []markupNode) string {
+	var (
+		blocks []string
+		inline []markupNode
+	)
+	flush := func() {
+		if text := strings.TrimSpace(renderInline(inline)); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline = nil
+	}
+	for _, node := range nodes {
+		if !isBlock(node.name) {
+			inline = append(inline, node)
+			continue
+		}
+		flush()
+		if block := renderBlock(node, ""); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+	return strings.Join(blocks, "\n\n")
+}
+
+// parseMarkup reads the XML fragment of a markup field into a tree, dropping namespaces.
+func parseMarkup(inner string) []markupNode {
+	decoder := xml.NewDecoder(strings.NewReader("<markup>" + inner + "</markup>"))
+	decoder.Strict = false
+
+	stack := []*markupNode{{}}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		top := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := markupNode{name: t.Name.Local, attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			top.children = append(top.children, node)
+			stack = append(stack, &top.children[len(top.children)-1])
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			top.children = append(top.children, markupNode{text: string(t)})
+		}
+	}
+
+	root := stack[0]
+	if len(root.children) == 1 && root.children[0].name == "markup" {
+		return root.children[0].children
+	}
+	return root.children
+}
+
+func isBlock(name string) bool {
+	switch name {
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "pre", "ul", "ol", "blockquote", "table":
+		return true
+	}
+	return false
+}
+
+// renderBlock converts a block element to markdown.
+func renderBlock(node markupNode, indent string) string {
+	switch node.name {
+	case "p":
+		return strings.TrimSpace(renderInline(node.children))
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return strings.Repeat("#", int(node.name[1]-'0')) + " " + strings.TrimSpace(renderInline(node.children))
+	case "pre":
+		return "```\n" + strings.Trim(textContent(node), "\n") + "\n```"
+	case "ul", "ol":
+		var items []string
+		for _, child := range node.children {
+			if child.name != "li" {
+				continue
+			}
+			marker := "- "
+			if node.name == "ol" {
+				marker = "1. "
+			}
+			var text, nested []string
+			for _, part := range child.children {
+				if part.name == "ul" || part.name == "ol" {
+					nested = append(nested, renderBlock(part, indent+"    "))
+					continue
+				}
+				text = append(text, renderInline([]markupNode{part}))
+			}
+			item := indent + marker + strings.TrimSpace(strings.Join(text, ""))
+			for _, list := range nested {
+				item += "\n" + list
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, "\n")
+	case "blockquote":
+		return "> " + strings.ReplaceAll(renderProse(node.children), "\n", "\n> ")
+	case "table":
+		var rows []string
+		for i, row := range tableRows(node) {
+			var cells []string
+			for _, cell := range row.children {
+				if cell.name == "th" || cell.name == "td" {
+					cells = append(cells, strings.TrimSpace(renderInline(cell.children)))
+				}
+			}
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		}
+		return strings.Join(rows, "\n")
+	}
+	return ""
+}
+
+func tableRows(node markupNode) []markupNode {
+	var rows []markupNode
+	for _, child := range node.children {
+		switch child.name {
+		case "tr":
+			rows = append(rows, child)
+		case "thead", "tbody", "tfoot":
+			rows = append(rows, tableRows(child)...)
+		}
+	}
+	return rows
+}
+
+// renderInline converts inline markup to markdown, collapsing the whitespace of pretty printed XML.
+func renderInline(nodes []markupNode) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch node.name {
+		case "":
+			b.WriteString(collapseSpace(node.text))
+		case "em", "i":
+			b.WriteString("*" + renderInline(node.children) + "*")
+		case "strong", "b":
+			b.WriteString("**" + renderInline(node.children) + "**")
+		case "code":
+			b.WriteString("`" + textContent(node) + "`")
+		case "q":
+			b.WriteString(`"` + renderInline(node.children) + `"`)
+		case "sub":
+			b.WriteString("~" + renderInline(node.children) + "~")
+		case "sup":
+			b.WriteString("^" + renderInline(node.children) + "^")
+		case "a":
+			b.WriteString("[" + renderInline(node.children) + "](" + node.attrs["href"] + ")")
+		case "img":
+			b.WriteString("![" + node.attrs["alt"] + "](" + node.attrs["src"] + ")")
+		case "insert":
+			b.WriteString("{{ insert: " + node.attrs["type"] + ", " + node.attrs["id-ref"] + " }}")
+		default:
+			b.WriteString(renderInline(node.children))
+		}
+	}
+	return strings.ReplaceAll(b.String(), "  ", " ")
+}
+
+// collapseSpace replaces each run of whitespace in text with a single space.
+func collapseSpace(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// textContent returns the text of a node and its descendants verbatim.
+func textContent(node markupNode) string {
+	if node.name == "" {
+		return node.text
+	}
+	var b strings.Builder
+	for _, child := range node.children {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+var (
+	headingLine   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listItemLine  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	tableDivider  = regexp.MustCompile(`^\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+	codeSpan      = regexp.MustCompile("`([^`]*)`")
+	inlineMarkups = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		{regexp.MustCompile(`\{\{\s*insert:\s*([\w-]+),\s*([^\s}]+)\s*\}\}`), `<insert type="$1" id-ref="$2"/>`},
+		{regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`), `<img alt="$1" src="$2"/>`},
+		{regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`), `<a href="$2">$1</a>`},
+		{regexp.MustCompile(`\*\*([^*]+)\*\*`), `<strong>$1</strong>`},
+		{regexp.MustCompile(`\*([^*\s][^*]*)\*`), `<em>$1</em>`},
+	}
+	markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// lineMarkup converts markdown to markup-line prose.
+func lineMarkup(text string) markup {
+	return markup{Inner: inlineMarkup(strings.TrimSpace(text))}
+}
+
+// optionalLineMarkup converts markdown to markup-line prose, omitting it when empty.
+func optionalLineMarkup(text string) *markup {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	m := lineMarkup(text)
+	return &m
+}
+
+// multilineMarkup converts markdown to markup-multiline prose.
+func multilineMarkup(text string) markup {
+	return markup{Inner: blockMarkup(strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"))}
+}
+
+// optionalMultilineMarkup converts markdown to markup-multiline prose, omitting it when empty.
+func optionalMultilineMarkup(text string) *markup {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	m := multilineMarkup(text)
+	return &m
+}
+
+func blockMarkup(lines []string) string {
+	var b strings.Builder
+
+	startsBlock := func(line string) bool {
+		trimmed := strings.TrimSpace(line)
+		return trimmed == "" || strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, ">") ||
+			strings.HasPrefix(trimmed, "|") || headingLine.MatchString(trimmed) || listItemLine.MatchString(line)
+	}
+	// collect returns the lines from i for as long as they satisfy keep
+	collect := func(i int, keep func(string) bool) []string {
+		j := i
+		for j < len(lines) && keep(lines[j]) {
+			j++
+		}
+		return lines[i:j]
+	}
+
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			i++
+		case strings.HasPrefix(trimmed, "```"):
+			code := collect(i+1, func(line string) bool { return !strings.HasPrefix(strings.TrimSpace(line), "```") })
+			b.WriteString("<pre>" + markupEscaper.Replace(strings.Join(code, "\n")) + "</pre>")
+			i += len(code) + 2
+		case headingLine.MatchString(trimmed):
+			heading := headingLine.FindStringSubmatch(trimmed)
+			tag := "h" + string(rune('0'+len(heading[1])))
+			b.WriteString("<" + tag + ">" + inlineMarkup(heading[2]) + "</" + tag + ">")
+			i++
+		case listItemLine.MatchString(lines[i]):
+			items := collect(i, func(line string) bool {
+				return strings.TrimSpace(line) != "" && (listItemLine.MatchString(line) || unicode.IsSpace(rune(line[0])))
+			})
+			b.WriteString(listMarkup(items))
+			i += len(items)
+		case strings.HasPrefix(trimmed, ">"):
+			quoted := collect(i, func(line string) bool { return strings.HasPrefix(strings.TrimSpace(line), ">") })
+			inner := make([]string, 0, len(quoted))
+			for _, line := range quoted {
+				line = strings.TrimPrefix(strings.TrimSpace(line), ">")
+				inner = append(inner, strings.TrimPrefix(line, " "))
+			}
+			b.WriteString("<blockquote>" + blockMarkup(inner) + "</blockquote>")
+			i += len(quoted)
+		case strings.HasPrefix(trimmed, "|"):
+			rows := collect(i, func(line string) bool { return strings.HasPrefix(strings.TrimSpace(line), "|") })
+			b.WriteString(tableMarkup(rows))
+			i += len(rows)
+		default:
+			paragraph := collect(i+1, func(line string) bool { return !startsBlock(line) })
+			paragraph = append([]string{lines[i]}, paragraph...)
+			for j := range paragraph {
+				paragraph[j] = strings.TrimSpace(paragraph[j])
+			}
+			b.WriteString("<p>" + inlineMarkup(strings.Join(paragraph, "\n")) + "</p>")
+			i += len(paragraph)
+		}
+	}
+	return b.String()
+}
+
+// listMarkup converts the lines of a markdown list, nesting more deeply indented items within the item before them.
+func listMarkup(lines []string) string {
+	first := listItemLine.FindStringSubmatch(lines[0])
+	indent, tag := len(first[1]), "ol"
+	if strings.ContainsAny(first[2], "-*+") {
+		tag = "ul"
+	}
+
+	var b strings.Builder
+	b.WriteString("<" + tag + ">")
+	for i := 0; i < len(lines); {
+		content := listItemLine.FindStringSubmatch(lines[i])[3]
+		var nested []string
+		for i++; i < len(lines); i++ {
+			item := listItemLine.FindStringSubmatch(lines[i])
+			if item != nil && len(item[1]) <= indent {
+				break
+			}
+			if item == nil && len(nested) == 0 {
+				content += "\n" + strings.TrimSpace(lines[i])
+				continue
+			}
+			nested = append(nested, lines[i])
+		}
+		b.WriteString("<li>" + inlineMarkup(content))
+		if len(nested) > 0 {
+			b.WriteString(listMarkup(nested))
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</" + tag + ">")
+	return b.String()
+}
+
+// tableMarkup converts a markdown table, whose first row is the header.
+func tableMarkup(rows []string) string {
+	var b strings.Builder
+	b.WriteString("<table>")
+	header := true
+	for _, row := range rows {
+		row = strings.TrimSpace(row)
+		if tableDivider.MatchString(row) {
+			continue
+		}
+		cell := "td"
+		if header {
+			cell, header = "th", false
+		}
+		b.WriteString("<tr>")
+		for _, text := range strings.Split(strings.Trim(row, "|"), "|") {
+			b.WriteString("<" + cell + ">" + inlineMarkup(strings.TrimSpace(text)) + "</" + cell + ">")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</table>")
+	return b.String()
+}
+
+// inlineMarkup escapes text and converts inline markdown to markup.
+func inlineMarkup(text string) string {
+	replace := func(text string) string {
+		text = markupEscaper.Replace(text)
+		for _, inline := range inlineMarkups {
+			text = inline.pattern.ReplaceAllString(text, inline.replacement)
+		}
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpan.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(replace(text[last:span[0]]))
+		b.WriteString("<code>" + markupEscaper.Replace(text[span[2]:span[3]]) + "</code>")
+		last = span[1]
+	}
+	b.WriteString(replace(text[last:]))
+	return b.String()
+}
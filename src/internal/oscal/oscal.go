@@ -1,45 +1,137 @@
 package oscal
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
-	"os"
+	"path"
+	"strings"
 
-	"github.com/defenseunicorns/component-generator/src/internal/http"
 	"github.com/defenseunicorns/component-generator/src/internal/types"
 	"gopkg.in/yaml.v2"
 )
 
-func GetOscalComponentDocumentFromRepo(repo string, tag string, path string) (oscalDocument types.OscalComponentDocument, err error) {
-	uri, err := http.ConstructURL(repo, tag, path)
-	if err != nil {
-		return oscalDocument, fmt.Errorf("failed to construct git URL: %w", err)
-	}
-	responseCode, bytes, err := http.FetchFromHTTPResource(uri)
-	if err != nil {
-		return oscalDocument, err
+// Format is a serialization of an OSCAL document.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+)
+
+// ParseFormat validates the name of a format.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatYAML, FormatJSON, FormatXML:
+		return format, nil
 	}
-	if responseCode != 200 {
-		return oscalDocument, fmt.Errorf("unexpected response code when downloading document: %v", responseCode)
+	return "", fmt.Errorf("unsupported format %q - must be one of yaml, json or xml", name)
+}
+
+// DetectFormat determines the format of a document from its extension, falling back to sniffing its content.
+func DetectFormat(name string, data []byte) Format {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".xml":
+		return FormatXML
+	case ".yaml", ".yml":
+		return FormatYAML
 	}
-	err = yaml.Unmarshal(bytes, &oscalDocument)
-	if err != nil {
-		return oscalDocument, err
+
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatXML
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	default:
+		return FormatYAML
 	}
+}
 
-	return oscalDocument, nil
+// ParseComponentDocument unmarshals an OSCAL component-definition in any format.
+func ParseComponentDocument(data []byte) (types.OscalComponentDocument, error) {
+	return ParseComponentDocumentAs(DetectFormat("", data), data)
 }
 
-func GetOscalComponentFromLocal(path string) (types.OscalComponentDocument, error) {
+// ParseComponentDocumentAs unmarshals the raw bytes of an OSCAL component-definition document in the given format.
+func ParseComponentDocumentAs(format Format, data []byte) (types.OscalComponentDocument, error) {
 	var document types.OscalComponentDocument
 
-	rawDoc, err := os.ReadFile(path)
-	if err != nil {
-		return document, err
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return document, err
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, &document); err != nil {
+			return document, err
+		}
+	case FormatXML:
+		var definition xmlComponentDefinition
+		if err := xml.Unmarshal(data, &definition); err != nil {
+			return document, err
+		}
+		document.ComponentDefinition = definition.toComponentDefinition()
+	default:
+		return document, fmt.Errorf("unsupported OSCAL format %q", format)
 	}
+	return document, nil
+}
 
-	err = yaml.Unmarshal(rawDoc, &document)
-	if err != nil {
-		return document, err
+// MarshalComponentDocument serializes an OSCAL component-definition in the given format.
+func MarshalComponentDocument(format Format, document types.OscalComponentDocument) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(document)
+	case FormatJSON:
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatXML:
+		data, err := xml.MarshalIndent(fromComponentDefinition(document.ComponentDefinition), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]byte(xml.Header), data...), '\n'), nil
 	}
-	return document, err
-}
\ No newline at end of file
+	return nil, fmt.Errorf("unsupported OSCAL format %q", format)
+}
+
+// IsComponentDefinition reports whether raw bytes hold a top level component-definition key or XML element.
+func IsComponentDefinition(data []byte) bool {
+	switch DetectFormat("", data) {
+	case FormatXML:
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return false
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				return start.Name.Local == "component-definition"
+			}
+		}
+	case FormatJSON:
+		var document map[string]json.RawMessage
+
+		if err := json.Unmarshal(data, &document); err != nil {
+			return false
+		}
+		_, ok := document["component-definition"]
+		return ok
+	default:
+		var document map[string]interface{}
+
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return false
+		}
+		_, ok := document["component-definition"]
+		return ok
+	}
+}
@@ -0,0 +1,205 @@
+package oscal
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected Format
+	}{
+		{name: "component.json", content: "component-definition: {}", expected: FormatJSON},
+		{name: "component.XML", content: "{}", expected: FormatXML},
+		{name: "component.yml", content: "<x/>", expected: FormatYAML},
+		{name: "", content: "\n  {\"component-definition\": {}}", expected: FormatJSON},
+		{name: "component", content: "\xef\xbb\xbf<?xml version=\"1.0\"?><component-definition/>", expected: FormatXML},
+		{name: "oci:sha256:abc", content: "component-definition:\n  uuid: 1\n", expected: FormatYAML},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, DetectFormat(tt.name, []byte(tt.content)), tt.name)
+	}
+}
+
+func TestIsComponentDefinition(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, IsComponentDefinition([]byte("component-definition:\n  uuid: 1\n")))
+	require.True(t, IsComponentDefinition([]byte("{\n\t\"component-definition\": {}\n}")))
+	require.True(t, IsComponentDefinition([]byte(`<?xml version="1.0"?><!-- comment --><component-definition xmlns="http://csrc.nist.gov/ns/oscal/1.0"/>`)))
+	require.False(t, IsComponentDefinition([]byte(`<catalog xmlns="http://csrc.nist.gov/ns/oscal/1.0"/>`)))
+	require.False(t, IsComponentDefinition([]byte(`{"catalog": {}}`)))
+	require.False(t, IsComponentDefinition([]byte("kind: Deployment\n")))
+}
+
+func TestParseComponentDocumentFormats(t *testing.T) {
+	t.Parallel()
+
+	jsonContent, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.json")
+	require.NoError(t, err)
+	fromJSON, err := ParseComponentDocument(jsonContent)
+	require.NoError(t, err)
+	require.Equal(t, "Jaeger", fromJSON.ComponentDefinition.Components[0].Title)
+
+	xmlContent, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.xml")
+	require.NoError(t, err)
+	fromXML, err := ParseComponentDocument(xmlContent)
+	require.NoError(t, err)
+	require.Equal(t, fromJSON, fromXML)
+
+	yamlContent, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.yaml")
+	require.NoError(t, err)
+	fromYAML, err := ParseComponentDocumentAs(FormatYAML, yamlContent)
+	require.NoError(t, err)
+	require.Equal(t, fromJSON.ComponentDefinition.UUID, fromYAML.ComponentDefinition.UUID)
+	require.Equal(t, fromJSON.ComponentDefinition.BackMatter, fromYAML.ComponentDefinition.BackMatter)
+
+	_, err = ParseComponentDocumentAs(FormatJSON, yamlContent)
+	require.Error(t, err)
+}
+
+func TestMarkup(t *testing.T) {
+	t.Parallel()
+
+	multiline := &markup{Inner: `
+		<p>Uses <em>mutual</em> TLS,
+		   see <a href="https://istio.io">Istio</a> &amp; <code>PeerAuthentication</code>.</p>
+		<ul>
+			<li>one <strong>two</strong></li>
+			<li>three<ol><li>nested</li></ol></li>
+		</ul>
+		<h2>Parameters</h2>
+		<p>Set to <insert type="param" id-ref="ac-2_prm_1"/>.</p>
+		<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>`}
+	require.Equal(t, "Uses *mutual* TLS, see [Istio](https://istio.io) & `PeerAuthentication`.\n\n"+
+		"- one **two**\n- three\n    1. nested\n\n"+
+		"## Parameters\n\n"+
+		"Set to {{ insert: param, ac-2_prm_1 }}.\n\n"+
+		"| a | b |\n| --- | --- |\n| 1 | 2 |", multiline.multiline())
+
+	require.Equal(t, "A <b>bold</b> title", (&markup{Inner: "  A &lt;b&gt;bold&lt;/b&gt;\n title "}).line())
+	require.Equal(t, "Text outside a paragraph", (&markup{Inner: "Text outside\na paragraph"}).multiline())
+
+	var missing *markup
+	require.Equal(t, "", missing.line())
+}
+
+func TestMarshalComponentDocument(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.json")
+	require.NoError(t, err)
+	document, err := ParseComponentDocument(content)
+	require.NoError(t, err)
+	document.ComponentDefinition.Components[0].Remarks = "Deployed with:\n\n- the *tracing* operator\n- `jaeger-query`"
+
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatXML} {
+		data, err := MarshalComponentDocument(format, document)
+		require.NoError(t, err, format)
+		require.Equal(t, format, DetectFormat("", data), format)
+
+		roundTripped, err := ParseComponentDocumentAs(format, data)
+		require.NoError(t, err, format)
+		require.Equal(t, document, roundTripped, format)
+	}
+
+	data, err := MarshalComponentDocument(FormatXML, document)
+	require.NoError(t, err)
+	xmlContent := string(data)
+	require.Contains(t, xmlContent, `<component-definition xmlns="http://csrc.nist.gov/ns/oscal/1.0" uuid="D69121EA-D112-41F8-8829-8638A173347A">`)
+	require.Contains(t, xmlContent, `<implemented-requirement uuid="1822457D-461B-482F-8564-8929C85C04DA" control-id="si-4.4">`)
+	require.Contains(t, xmlContent, "<remarks><p>Deployed with:</p><ul><li>the <em>tracing</em> operator</li><li><code>jaeger-query</code></li></ul></remarks>")
+
+	// Elements must appear in the order the OSCAL XML schema requires
+	order := []string{"<metadata>", "<title>", "<last-modified>", "<version>", "<oscal-version>", "<party ", "<component ",
+		"<description>", "<purpose>", "<responsible-role ", "<control-implementation ", "<remarks>", "<back-matter>", "<resource "}
+	last := -1
+	for _, element := range order {
+		index := strings.Index(xmlContent[last+1:], element)
+		require.GreaterOrEqual(t, index, 0, element)
+		last += index + 1
+	}
+
+	_, err = MarshalComponentDocument("toml", document)
+	require.ErrorContains(t, err, `unsupported OSCAL format "toml"`)
+}
+
+func TestMultilineMarkup(t *testing.T) {
+	t.Parallel()
+
+	text := "# Overview\n\nUses **mutual** TLS & [Istio](https://istio.io?a=1&b=2),\nset to {{ insert: param, ac-2_prm_1 }}.\n\n" +
+		"1. first\n   continued\n    - nested\n2. second\n\n> quoted\n\n```\n<raw> *text*\n```\n\n| a | b |\n| --- | --- |\n| 1 | 2 |"
+	require.Equal(t, "<h1>Overview</h1>"+
+		`<p>Uses <strong>mutual</strong> TLS &amp; <a href="https://istio.io?a=1&amp;b=2">Istio</a>,`+"\n"+`set to <insert type="param" id-ref="ac-2_prm_1"/>.</p>`+
+		"<ol><li>first\ncontinued<ul><li>nested</li></ul></li><li>second</li></ol>"+
+		"<blockquote><p>quoted</p></blockquote>"+
+		"<pre>&lt;raw&gt; *text*</pre>"+
+		"<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>", multilineMarkup(text).Inner)
+
+	// Converting back to markdown is the inverse, up to normalization of list markers and line breaks
+	require.Equal(t, "# Overview\n\nUses **mutual** TLS & [Istio](https://istio.io?a=1&b=2), set to {{ insert: param, ac-2_prm_1 }}.\n\n"+
+		"1. first continued\n    - nested\n1. second\n\n> quoted\n\n```\n<raw> *text*\n```\n\n| a | b |\n| --- | --- |\n| 1 | 2 |", optionalMultilineMarkup(text).multiline())
+	require.Nil(t, optionalMultilineMarkup(" \n"))
+}
+
+func TestCompareNatural(t *testing.T) {
+	t.Parallel()
+
+	sorted := []string{"ac-1", "ac-2", "AC-2.1", "ac-2.2", "ac-2.10", "ac-10", "ac-10.1", "au-2", "au-02a", "sc-7"}
+	for i := range sorted {
+		for j := range sorted {
+			c := CompareNatural(sorted[i], sorted[j])
+			switch {
+			case i < j:
+				require.Negative(t, c, "%s < %s", sorted[i], sorted[j])
+			case i > j:
+				require.Positive(t, c, "%s > %s", sorted[i], sorted[j])
+			default:
+				require.Zero(t, c, sorted[i])
+			}
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	document := types.OscalComponentDocument{ComponentDefinition: types.ComponentDefinition{
+		Components: []types.DefinedComponent{
+			{UUID: "B", Title: " zarf\r\n", Description: "\n  \r\n    indented code\r\nhard  \nbreak\n\n", Purpose: "  Purpose  "},
+			{UUID: "C", Title: "Jaeger", ControlImplementations: []types.ControlImplementation{
+				{UUID: "2", Source: "https://example.com/rev5", ImplementedRequirements: []types.ImplementedRequirement{
+					{UUID: "x", ControlId: "ac-10"},
+					{UUID: "y", ControlId: "ac-2.1", Statements: []types.Statement{{StatementId: "ac-2.1_smt.b"}, {StatementId: "ac-2.1_smt.a"}}},
+					{UUID: "z", ControlId: "ac-2"},
+				}},
+				{UUID: "1", Source: "https://example.com/rev4"},
+			}},
+			{UUID: "A", Title: "Jaeger"},
+		},
+		BackMatter: types.BackMatter{Resources: []types.Resources{{UUID: "f"}, {UUID: "E"}}},
+	}}
+	Canonicalize(&document)
+
+	definition := document.ComponentDefinition
+	require.Equal(t, []string{"A", "C", "B"}, convert(definition.Components, func(c types.DefinedComponent) string { return c.UUID }))
+	require.Equal(t, "zarf", definition.Components[2].Title)
+	require.Equal(t, "Purpose", definition.Components[2].Purpose)
+	// Prose keeps its indentation and trailing hard line breaks
+	require.Equal(t, "    indented code\nhard  \nbreak", definition.Components[2].Description)
+
+	implementations := definition.Components[1].ControlImplementations
+	require.Equal(t, "1", implementations[0].UUID)
+	requirements := implementations[1].ImplementedRequirements
+	require.Equal(t, []string{"ac-2", "ac-2.1", "ac-10"}, convert(requirements, func(r types.ImplementedRequirement) string { return r.ControlId }))
+	require.Equal(t, "ac-2.1_smt.a", requirements[1].Statements[0].StatementId)
+	require.Equal(t, []string{"E", "f"}, convert(definition.BackMatter.Resources, func(r types.Resources) string { return r.UUID }))
+}
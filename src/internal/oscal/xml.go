@@ -0,0 +1,280 @@
+package oscal
+
+import (
+	"encoding/xml"
+)
+
+// The xml* types mirror the OSCAL XML serialization of a component-definition, in the element order it requires.
+
+type xmlComponentDefinition struct {
+	XMLName      xml.Name        `xml:"component-definition"`
+	Namespace    string          `xml:"xmlns,attr,omitempty"`
+	UUID         string          `xml:"uuid,attr"`
+	Metadata     xmlMetadata     `xml:"metadata"`
+	Imports      []xmlImport     `xml:"import-component-definition"`
+	Components   []xmlComponent  `xml:"component"`
+	Capabilities []xmlCapability `xml:"capability"`
+	BackMatter   *xmlBackMatter  `xml:"back-matter"`
+}
+
+type xmlMetadata struct {
+	Title              markup                `xml:"title"`
+	Published          string                `xml:"published,omitempty"`
+	LastModified       string                `xml:"last-modified"`
+	Version            string                `xml:"version"`
+	OscalVersion       string                `xml:"oscal-version"`
+	Revisions          *xmlRevisions         `xml:"revisions"`
+	DocumentIds        []xmlDocumentId       `xml:"document-id"`
+	Props              []xmlProp             `xml:"prop"`
+	Links              []xmlLink             `xml:"link"`
+	Roles              []xmlRole             `xml:"role"`
+	Locations          []xmlLocation         `xml:"location"`
+	Parties            []xmlParty            `xml:"party"`
+	ResponsibleParties []xmlResponsibleParty `xml:"responsible-party"`
+	Remarks            *markup               `xml:"remarks"`
+}
+
+type xmlRevisions struct {
+	Revisions []xmlRevision `xml:"revision"`
+}
+
+type xmlRevision struct {
+	Title        *markup   `xml:"title"`
+	Published    string    `xml:"published,omitempty"`
+	LastModified string    `xml:"last-modified,omitempty"`
+	Version      string    `xml:"version"`
+	OscalVersion string    `xml:"oscal-version,omitempty"`
+	Props        []xmlProp `xml:"prop"`
+	Links        []xmlLink `xml:"link"`
+	Remarks      *markup   `xml:"remarks"`
+}
+
+type xmlDocumentId struct {
+	Scheme     string `xml:"scheme,attr,omitempty"`
+	Identifier string `xml:",chardata"`
+}
+
+type xmlProp struct {
+	Name    string  `xml:"name,attr"`
+	UUID    string  `xml:"uuid,attr,omitempty"`
+	Ns      string  `xml:"ns,attr,omitempty"`
+	Value   string  `xml:"value,attr"`
+	Class   string  `xml:"class,attr,omitempty"`
+	Remarks *markup `xml:"remarks"`
+}
+
+type xmlLink struct {
+	Href      string  `xml:"href,attr"`
+	Rel       string  `xml:"rel,attr,omitempty"`
+	MediaType string  `xml:"media-type,attr,omitempty"`
+	Text      *markup `xml:"text"`
+}
+
+type xmlRole struct {
+	ID          string    `xml:"id,attr"`
+	Title       markup    `xml:"title"`
+	ShortName   string    `xml:"short-name,omitempty"`
+	Description *markup   `xml:"description"`
+	Props       []xmlProp `xml:"prop"`
+	Links       []xmlLink `xml:"link"`
+	Remarks     *markup   `xml:"remarks"`
+}
+
+type xmlLocation struct {
+	UUID             string               `xml:"uuid,attr"`
+	Title            *markup              `xml:"title"`
+	Address          *xmlAddress          `xml:"address"`
+	EmailAddresses   []string             `xml:"email-address"`
+	TelephoneNumbers []xmlTelephoneNumber `xml:"telephone-number"`
+	Urls             []string             `xml:"url"`
+	Props            []xmlProp            `xml:"prop"`
+	Links            []xmlLink            `xml:"link"`
+	Remarks          *markup              `xml:"remarks"`
+}
+
+type xmlAddress struct {
+	Type       string   `xml:"type,attr,omitempty"`
+	AddrLines  []string `xml:"addr-line"`
+	City       string   `xml:"city,omitempty"`
+	State      string   `xml:"state,omitempty"`
+	PostalCode string   `xml:"postal-code,omitempty"`
+	Country    string   `xml:"country,omitempty"`
+}
+
+type xmlTelephoneNumber struct {
+	Type   string `xml:"type,attr,omitempty"`
+	Number string `xml:",chardata"`
+}
+
+type xmlParty struct {
+	UUID                  string               `xml:"uuid,attr"`
+	Type                  string               `xml:"type,attr"`
+	Name                  string               `xml:"name,omitempty"`
+	ShortName             string               `xml:"short-name,omitempty"`
+	ExternalIds           []xmlExternalId      `xml:"external-id"`
+	Props                 []xmlProp            `xml:"prop"`
+	Links                 []xmlLink            `xml:"link"`
+	EmailAddresses        []string             `xml:"email-address"`
+	TelephoneNumbers      []xmlTelephoneNumber `xml:"telephone-number"`
+	Addresses             []xmlAddress         `xml:"address"`
+	LocationUuids         []string             `xml:"location-uuid"`
+	MemberOfOrganizations []string             `xml:"member-of-organization"`
+	Remarks               *markup              `xml:"remarks"`
+}
+
+type xmlExternalId struct {
+	Scheme string `xml:"scheme,attr"`
+	ID     string `xml:",chardata"`
+}
+
+type xmlResponsibleParty struct {
+	RoleId     string    `xml:"role-id,attr"`
+	PartyUuids []string  `xml:"party-uuid"`
+	Props      []xmlProp `xml:"prop"`
+	Links      []xmlLink `xml:"link"`
+	Remarks    *markup   `xml:"remarks"`
+}
+
+type xmlImport struct {
+	Href string `xml:"href,attr"`
+}
+
+type xmlComponent struct {
+	UUID                   string                     `xml:"uuid,attr"`
+	Type                   string                     `xml:"type,attr"`
+	Title                  markup                     `xml:"title"`
+	Description            markup                     `xml:"description"`
+	Purpose                *markup                    `xml:"purpose"`
+	Props                  []xmlProp                  `xml:"prop"`
+	Links                  []xmlLink                  `xml:"link"`
+	ResponsibleRoles       []xmlResponsibleRole       `xml:"responsible-role"`
+	Protocols              []xmlProtocol              `xml:"protocol"`
+	ControlImplementations []xmlControlImplementation `xml:"control-implementation"`
+	Remarks                *markup                    `xml:"remarks"`
+}
+
+type xmlResponsibleRole struct {
+	RoleId     string    `xml:"role-id,attr"`
+	Props      []xmlProp `xml:"prop"`
+	Links      []xmlLink `xml:"link"`
+	PartyUuids []string  `xml:"party-uuid"`
+	Remarks    *markup   `xml:"remarks"`
+}
+
+type xmlProtocol struct {
+	UUID       string         `xml:"uuid,attr,omitempty"`
+	Name       string         `xml:"name,attr"`
+	Title      *markup        `xml:"title"`
+	PortRanges []xmlPortRange `xml:"port-range"`
+}
+
+type xmlPortRange struct {
+	Start     int    `xml:"start,attr,omitempty"`
+	End       int    `xml:"end,attr,omitempty"`
+	Transport string `xml:"transport,attr,omitempty"`
+}
+
+type xmlControlImplementation struct {
+	UUID                    string                      `xml:"uuid,attr"`
+	Source                  string                      `xml:"source,attr"`
+	Description             markup                      `xml:"description"`
+	Props                   []xmlProp                   `xml:"prop"`
+	Links                   []xmlLink                   `xml:"link"`
+	SetParameters           []xmlSetParameter           `xml:"set-parameter"`
+	ImplementedRequirements []xmlImplementedRequirement `xml:"implemented-requirement"`
+}
+
+type xmlSetParameter struct {
+	ParamId string   `xml:"param-id,attr"`
+	Values  []string `xml:"value"`
+	Remarks *markup  `xml:"remarks"`
+}
+
+type xmlImplementedRequirement struct {
+	UUID             string               `xml:"uuid,attr"`
+	ControlId        string               `xml:"control-id,attr"`
+	Description      markup               `xml:"description"`
+	Props            []xmlProp            `xml:"prop"`
+	Links            []xmlLink            `xml:"link"`
+	SetParameters    []xmlSetParameter    `xml:"set-parameter"`
+	ResponsibleRoles []xmlResponsibleRole `xml:"responsible-role"`
+	Statements       []xmlStatement       `xml:"statement"`
+	Remarks          *markup              `xml:"remarks"`
+}
+
+type xmlStatement struct {
+	StatementId      string               `xml:"statement-id,attr"`
+	UUID             string               `xml:"uuid,attr"`
+	Description      markup               `xml:"description"`
+	Props            []xmlProp            `xml:"prop"`
+	Links            []xmlLink            `xml:"link"`
+	ResponsibleRoles []xmlResponsibleRole `xml:"responsible-role"`
+	Remarks          *markup              `xml:"remarks"`
+}
+
+type xmlCapability struct {
+	UUID                   string                     `xml:"uuid,attr"`
+	Name                   string                     `xml:"name,attr"`
+	Description            markup                     `xml:"description"`
+	Props                  []xmlProp                  `xml:"prop"`
+	Links                  []xmlLink                  `xml:"link"`
+	IncorporatesComponents []xmlIncorporatesComponent `xml:"incorporates-component"`
+	ControlImplementations []xmlControlImplementation `xml:"control-implementation"`
+	Remarks                *markup                    `xml:"remarks"`
+}
+
+type xmlIncorporatesComponent struct {
+	ComponentUuid string `xml:"component-uuid,attr"`
+	Description   markup `xml:"description"`
+}
+
+type xmlBackMatter struct {
+	Resources []xmlResource `xml:"resource"`
+}
+
+type xmlResource struct {
+	UUID        string          `xml:"uuid,attr"`
+	Title       *markup         `xml:"title"`
+	Description *markup         `xml:"description"`
+	Props       []xmlProp       `xml:"prop"`
+	DocumentIds []xmlDocumentId `xml:"document-id"`
+	Citation    *xmlCitation    `xml:"citation"`
+	Rlinks      []xmlRlink      `xml:"rlink"`
+	Base64      *xmlBase64      `xml:"base64"`
+	Remarks     *markup         `xml:"remarks"`
+}
+
+type xmlCitation struct {
+	Text  markup    `xml:"text"`
+	Props []xmlProp `xml:"prop"`
+	Links []xmlLink `xml:"link"`
+}
+
+type xmlRlink struct {
+	Href      string    `xml:"href,attr"`
+	MediaType string    `xml:"media-type,attr,omitempty"`
+	Hashes    []xmlHash `xml:"hash"`
+}
+
+type xmlHash struct {
+	Algorithm string `xml:"algorithm,attr"`
+	Value     string `xml:",chardata"`
+}
+
+type xmlBase64 struct {
+	Filename  string `xml:"filename,attr,omitempty"`
+	MediaType string `xml:"media-type,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+// convert applies f to every element of a slice, keeping nil slices nil so empty fields stay omitted.
+func convert[T, U any](in []T, f func(T) U) []U {
+	if in == nil {
+		return nil
+	}
+	out := make([]U, 0, len(in))
+	for _, v := range in {
+		out = append(out, f(v))
+	}
+	return out
+}
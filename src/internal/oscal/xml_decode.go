@@ -0,0 +1,280 @@
+package oscal
+
+import (
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+)
+
+// toComponentDefinition converts a component-definition read from XML into the types shared with JSON and YAML.
+func (d xmlComponentDefinition) toComponentDefinition() types.ComponentDefinition {
+	definition := types.ComponentDefinition{
+		UUID:     d.UUID,
+		Metadata: d.Metadata.toMetadata(),
+		ImportComponentDefinitions: convert(d.Imports, func(i xmlImport) types.ImportComponentDefinition {
+			return types.ImportComponentDefinition{Href: i.Href}
+		}),
+		Components:   convert(d.Components, xmlComponent.toComponent),
+		Capabilities: convert(d.Capabilities, xmlCapability.toCapability),
+	}
+	if d.BackMatter != nil {
+		definition.BackMatter.Resources = convert(d.BackMatter.Resources, xmlResource.toResource)
+	}
+	return definition
+}
+
+func (m xmlMetadata) toMetadata() types.Metadata {
+	metadata := types.Metadata{
+		Title:        m.Title.line(),
+		Published:    m.Published,
+		LastModified: m.LastModified,
+		Version:      m.Version,
+		OscalVersion: m.OscalVersion,
+		DocumentIds:  convert(m.DocumentIds, xmlDocumentId.toDocumentId),
+		Props:        convert(m.Props, xmlProp.toProperty),
+		Links:        convert(m.Links, xmlLink.toLink),
+		Roles:        convert(m.Roles, xmlRole.toRole),
+		Locations:    convert(m.Locations, xmlLocation.toLocation),
+		Parties:      convert(m.Parties, xmlParty.toParty),
+		ResponsibleParties: convert(m.ResponsibleParties, func(p xmlResponsibleParty) types.ResponsibleParty {
+			return types.ResponsibleParty{
+				RoleId:     p.RoleId,
+				PartyUuids: trimAll(p.PartyUuids),
+				Props:      convert(p.Props, xmlProp.toProperty),
+				Links:      convert(p.Links, xmlLink.toLink),
+				Remarks:    p.Remarks.multiline(),
+			}
+		}),
+		Remarks: m.Remarks.multiline(),
+	}
+	if m.Revisions != nil {
+		metadata.Revisions = convert(m.Revisions.Revisions, xmlRevision.toRevision)
+	}
+	return metadata
+}
+
+func (r xmlRevision) toRevision() types.Revision {
+	return types.Revision{
+		Title:        r.Title.line(),
+		Published:    r.Published,
+		LastModified: r.LastModified,
+		Version:      r.Version,
+		OscalVersion: r.OscalVersion,
+		Props:        convert(r.Props, xmlProp.toProperty),
+		Links:        convert(r.Links, xmlLink.toLink),
+		Remarks:      r.Remarks.multiline(),
+	}
+}
+
+func (d xmlDocumentId) toDocumentId() types.DocumentId {
+	return types.DocumentId{Scheme: d.Scheme, Identifier: strings.TrimSpace(d.Identifier)}
+}
+
+func (p xmlProp) toProperty() types.Property {
+	return types.Property{
+		Name:    p.Name,
+		UUID:    p.UUID,
+		Ns:      p.Ns,
+		Value:   p.Value,
+		Class:   p.Class,
+		Remarks: p.Remarks.multiline(),
+	}
+}
+
+func (l xmlLink) toLink() types.Link {
+	return types.Link{Href: l.Href, Rel: l.Rel, MediaType: l.MediaType, Text: l.Text.line()}
+}
+
+func (r xmlRole) toRole() types.Role {
+	return types.Role{
+		ID:          r.ID,
+		Title:       r.Title.line(),
+		ShortName:   r.ShortName,
+		Description: r.Description.multiline(),
+		Props:       convert(r.Props, xmlProp.toProperty),
+		Links:       convert(r.Links, xmlLink.toLink),
+		Remarks:     r.Remarks.multiline(),
+	}
+}
+
+func (l xmlLocation) toLocation() types.Location {
+	location := types.Location{
+		UUID:             l.UUID,
+		Title:            l.Title.line(),
+		EmailAddresses:   trimAll(l.EmailAddresses),
+		TelephoneNumbers: convert(l.TelephoneNumbers, xmlTelephoneNumber.toTelephoneNumber),
+		Urls:             trimAll(l.Urls),
+		Props:            convert(l.Props, xmlProp.toProperty),
+		Links:            convert(l.Links, xmlLink.toLink),
+		Remarks:          l.Remarks.multiline(),
+	}
+	if l.Address != nil {
+		location.Address = l.Address.toAddress()
+	}
+	return location
+}
+
+func (a xmlAddress) toAddress() types.Address {
+	return types.Address{
+		Type:       a.Type,
+		AddrLines:  trimAll(a.AddrLines),
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func (t xmlTelephoneNumber) toTelephoneNumber() types.TelephoneNumber {
+	return types.TelephoneNumber{Type: t.Type, Number: strings.TrimSpace(t.Number)}
+}
+
+func (p xmlParty) toParty() types.Party {
+	return types.Party{
+		UUID:      p.UUID,
+		Type:      p.Type,
+		Name:      p.Name,
+		ShortName: p.ShortName,
+		ExternalIds: convert(p.ExternalIds, func(e xmlExternalId) types.ExternalIds {
+			return types.ExternalIds{Scheme: e.Scheme, ID: strings.TrimSpace(e.ID)}
+		}),
+		Props:                 convert(p.Props, xmlProp.toProperty),
+		Links:                 convert(p.Links, xmlLink.toLink),
+		EmailAddresses:        trimAll(p.EmailAddresses),
+		TelephoneNumbers:      convert(p.TelephoneNumbers, xmlTelephoneNumber.toTelephoneNumber),
+		Addresses:             convert(p.Addresses, xmlAddress.toAddress),
+		LocationUuids:         trimAll(p.LocationUuids),
+		MemberOfOrganizations: trimAll(p.MemberOfOrganizations),
+		Remarks:               p.Remarks.multiline(),
+	}
+}
+
+func (c xmlComponent) toComponent() types.DefinedComponent {
+	return types.DefinedComponent{
+		UUID:             c.UUID,
+		Type:             c.Type,
+		Title:            c.Title.line(),
+		Description:      c.Description.multiline(),
+		Purpose:          c.Purpose.line(),
+		Props:            convert(c.Props, xmlProp.toProperty),
+		Links:            convert(c.Links, xmlLink.toLink),
+		ResponsibleRoles: convert(c.ResponsibleRoles, xmlResponsibleRole.toResponsibleRole),
+		Protocols: convert(c.Protocols, func(p xmlProtocol) types.Protocol {
+			return types.Protocol{
+				UUID:  p.UUID,
+				Name:  p.Name,
+				Title: p.Title.line(),
+				PortRanges: convert(p.PortRanges, func(r xmlPortRange) types.PortRange {
+					return types.PortRange{Start: r.Start, End: r.End, Transport: r.Transport}
+				}),
+			}
+		}),
+		ControlImplementations: convert(c.ControlImplementations, xmlControlImplementation.toControlImplementation),
+		Remarks:                c.Remarks.multiline(),
+	}
+}
+
+func (r xmlResponsibleRole) toResponsibleRole() types.ResponsibleRole {
+	return types.ResponsibleRole{
+		RoleId:     r.RoleId,
+		Props:      convert(r.Props, xmlProp.toProperty),
+		Links:      convert(r.Links, xmlLink.toLink),
+		PartyUuids: trimAll(r.PartyUuids),
+		Remarks:    r.Remarks.multiline(),
+	}
+}
+
+func (c xmlControlImplementation) toControlImplementation() types.ControlImplementation {
+	return types.ControlImplementation{
+		UUID:                    c.UUID,
+		Source:                  c.Source,
+		Description:             c.Description.multiline(),
+		Props:                   convert(c.Props, xmlProp.toProperty),
+		Links:                   convert(c.Links, xmlLink.toLink),
+		SetParameters:           convert(c.SetParameters, xmlSetParameter.toSetParameter),
+		ImplementedRequirements: convert(c.ImplementedRequirements, xmlImplementedRequirement.toImplementedRequirement),
+	}
+}
+
+func (s xmlSetParameter) toSetParameter() types.SetParameter {
+	return types.SetParameter{ParamId: s.ParamId, Values: trimAll(s.Values), Remarks: s.Remarks.multiline()}
+}
+
+func (r xmlImplementedRequirement) toImplementedRequirement() types.ImplementedRequirement {
+	return types.ImplementedRequirement{
+		UUID:             r.UUID,
+		ControlId:        r.ControlId,
+		Description:      r.Description.multiline(),
+		Props:            convert(r.Props, xmlProp.toProperty),
+		Links:            convert(r.Links, xmlLink.toLink),
+		SetParameters:    convert(r.SetParameters, xmlSetParameter.toSetParameter),
+		ResponsibleRoles: convert(r.ResponsibleRoles, xmlResponsibleRole.toResponsibleRole),
+		Statements: convert(r.Statements, func(s xmlStatement) types.Statement {
+			return types.Statement{
+				StatementId:      s.StatementId,
+				UUID:             s.UUID,
+				Description:      s.Description.multiline(),
+				Props:            convert(s.Props, xmlProp.toProperty),
+				Links:            convert(s.Links, xmlLink.toLink),
+				ResponsibleRoles: convert(s.ResponsibleRoles, xmlResponsibleRole.toResponsibleRole),
+				Remarks:          s.Remarks.multiline(),
+			}
+		}),
+		Remarks: r.Remarks.multiline(),
+	}
+}
+
+func (c xmlCapability) toCapability() types.Capability {
+	return types.Capability{
+		UUID:        c.UUID,
+		Name:        c.Name,
+		Description: c.Description.multiline(),
+		Props:       convert(c.Props, xmlProp.toProperty),
+		Links:       convert(c.Links, xmlLink.toLink),
+		IncorporatesComponents: convert(c.IncorporatesComponents, func(i xmlIncorporatesComponent) types.IncorporatesComponent {
+			return types.IncorporatesComponent{ComponentUuid: i.ComponentUuid, Description: i.Description.multiline()}
+		}),
+		ControlImplementations: convert(c.ControlImplementations, xmlControlImplementation.toControlImplementation),
+		Remarks:                c.Remarks.multiline(),
+	}
+}
+
+func (r xmlResource) toResource() types.Resources {
+	resource := types.Resources{
+		UUID:        r.UUID,
+		Title:       r.Title.line(),
+		Description: r.Description.multiline(),
+		Props:       convert(r.Props, xmlProp.toProperty),
+		DocumentIds: convert(r.DocumentIds, xmlDocumentId.toDocumentId),
+		Rlinks: convert(r.Rlinks, func(l xmlRlink) types.Rlinks {
+			return types.Rlinks{
+				Href:      l.Href,
+				MediaType: l.MediaType,
+				Hashes: convert(l.Hashes, func(h xmlHash) types.Hash {
+					return types.Hash{Algorithm: h.Algorithm, Value: strings.TrimSpace(h.Value)}
+				}),
+			}
+		}),
+		Remarks: r.Remarks.multiline(),
+	}
+	if r.Citation != nil {
+		resource.Citation = &types.Citation{
+			Text:  r.Citation.Text.line(),
+			Props: convert(r.Citation.Props, xmlProp.toProperty),
+			Links: convert(r.Citation.Links, xmlLink.toLink),
+		}
+	}
+	if r.Base64 != nil {
+		resource.Base64 = &types.Base64{
+			Filename:  r.Base64.Filename,
+			MediaType: r.Base64.MediaType,
+			Value:     strings.TrimSpace(r.Base64.Value),
+		}
+	}
+	return resource
+}
+
+// trimAll trims the whitespace XML pretty printing leaves around simple values.
+func trimAll(values []string) []string {
+	return convert(values, strings.TrimSpace)
+}
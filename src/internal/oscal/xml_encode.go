@@ -0,0 +1,272 @@
+package oscal
+
+import "github.com/defenseunicorns/component-generator/src/internal/types"
+
+// Namespace is the XML namespace of OSCAL documents.
+const Namespace = "http://csrc.nist.gov/ns/oscal/1.0"
+
+// fromComponentDefinition converts a component-definition to its XML serialization.
+func fromComponentDefinition(d types.ComponentDefinition) xmlComponentDefinition {
+	definition := xmlComponentDefinition{
+		Namespace: Namespace,
+		UUID:      d.UUID,
+		Metadata:  fromMetadata(d.Metadata),
+		Imports: convert(d.ImportComponentDefinitions, func(i types.ImportComponentDefinition) xmlImport {
+			return xmlImport{Href: i.Href}
+		}),
+		Components:   convert(d.Components, fromComponent),
+		Capabilities: convert(d.Capabilities, fromCapability),
+	}
+	if len(d.BackMatter.Resources) > 0 {
+		definition.BackMatter = &xmlBackMatter{Resources: convert(d.BackMatter.Resources, fromResource)}
+	}
+	return definition
+}
+
+func fromMetadata(m types.Metadata) xmlMetadata {
+	metadata := xmlMetadata{
+		Title:        lineMarkup(m.Title),
+		Published:    m.Published,
+		LastModified: m.LastModified,
+		Version:      m.Version,
+		OscalVersion: m.OscalVersion,
+		DocumentIds:  convert(m.DocumentIds, fromDocumentId),
+		Props:        convert(m.Props, fromProperty),
+		Links:        convert(m.Links, fromLink),
+		Roles:        convert(m.Roles, fromRole),
+		Locations:    convert(m.Locations, fromLocation),
+		Parties:      convert(m.Parties, fromParty),
+		ResponsibleParties: convert(m.ResponsibleParties, func(p types.ResponsibleParty) xmlResponsibleParty {
+			return xmlResponsibleParty{
+				RoleId:     p.RoleId,
+				PartyUuids: p.PartyUuids,
+				Props:      convert(p.Props, fromProperty),
+				Links:      convert(p.Links, fromLink),
+				Remarks:    optionalMultilineMarkup(p.Remarks),
+			}
+		}),
+		Remarks: optionalMultilineMarkup(m.Remarks),
+	}
+	if len(m.Revisions) > 0 {
+		metadata.Revisions = &xmlRevisions{Revisions: convert(m.Revisions, fromRevision)}
+	}
+	return metadata
+}
+
+func fromRevision(r types.Revision) xmlRevision {
+	return xmlRevision{
+		Title:        optionalLineMarkup(r.Title),
+		Published:    r.Published,
+		LastModified: r.LastModified,
+		Version:      r.Version,
+		OscalVersion: r.OscalVersion,
+		Props:        convert(r.Props, fromProperty),
+		Links:        convert(r.Links, fromLink),
+		Remarks:      optionalMultilineMarkup(r.Remarks),
+	}
+}
+
+func fromDocumentId(d types.DocumentId) xmlDocumentId {
+	return xmlDocumentId{Scheme: d.Scheme, Identifier: d.Identifier}
+}
+
+func fromProperty(p types.Property) xmlProp {
+	return xmlProp{
+		Name:    p.Name,
+		UUID:    p.UUID,
+		Ns:      p.Ns,
+		Value:   p.Value,
+		Class:   p.Class,
+		Remarks: optionalMultilineMarkup(p.Remarks),
+	}
+}
+
+func fromLink(l types.Link) xmlLink {
+	return xmlLink{Href: l.Href, Rel: l.Rel, MediaType: l.MediaType, Text: optionalLineMarkup(l.Text)}
+}
+
+func fromRole(r types.Role) xmlRole {
+	return xmlRole{
+		ID:          r.ID,
+		Title:       lineMarkup(r.Title),
+		ShortName:   r.ShortName,
+		Description: optionalMultilineMarkup(r.Description),
+		Props:       convert(r.Props, fromProperty),
+		Links:       convert(r.Links, fromLink),
+		Remarks:     optionalMultilineMarkup(r.Remarks),
+	}
+}
+
+func fromLocation(l types.Location) xmlLocation {
+	location := xmlLocation{
+		UUID:             l.UUID,
+		Title:            optionalLineMarkup(l.Title),
+		EmailAddresses:   l.EmailAddresses,
+		TelephoneNumbers: convert(l.TelephoneNumbers, fromTelephoneNumber),
+		Urls:             l.Urls,
+		Props:            convert(l.Props, fromProperty),
+		Links:            convert(l.Links, fromLink),
+		Remarks:          optionalMultilineMarkup(l.Remarks),
+	}
+	if address := fromAddress(l.Address); address.Type != "" || len(address.AddrLines) > 0 || address.City != "" ||
+		address.State != "" || address.PostalCode != "" || address.Country != "" {
+		location.Address = &address
+	}
+	return location
+}
+
+func fromAddress(a types.Address) xmlAddress {
+	return xmlAddress{
+		Type:       a.Type,
+		AddrLines:  a.AddrLines,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func fromTelephoneNumber(t types.TelephoneNumber) xmlTelephoneNumber {
+	return xmlTelephoneNumber{Type: t.Type, Number: t.Number}
+}
+
+func fromParty(p types.Party) xmlParty {
+	return xmlParty{
+		UUID:      p.UUID,
+		Type:      p.Type,
+		Name:      p.Name,
+		ShortName: p.ShortName,
+		ExternalIds: convert(p.ExternalIds, func(e types.ExternalIds) xmlExternalId {
+			return xmlExternalId{Scheme: e.Scheme, ID: e.ID}
+		}),
+		Props:                 convert(p.Props, fromProperty),
+		Links:                 convert(p.Links, fromLink),
+		EmailAddresses:        p.EmailAddresses,
+		TelephoneNumbers:      convert(p.TelephoneNumbers, fromTelephoneNumber),
+		Addresses:             convert(p.Addresses, fromAddress),
+		LocationUuids:         p.LocationUuids,
+		MemberOfOrganizations: p.MemberOfOrganizations,
+		Remarks:               optionalMultilineMarkup(p.Remarks),
+	}
+}
+
+func fromComponent(c types.DefinedComponent) xmlComponent {
+	return xmlComponent{
+		UUID:             c.UUID,
+		Type:             c.Type,
+		Title:            lineMarkup(c.Title),
+		Description:      multilineMarkup(c.Description),
+		Purpose:          optionalLineMarkup(c.Purpose),
+		Props:            convert(c.Props, fromProperty),
+		Links:            convert(c.Links, fromLink),
+		ResponsibleRoles: convert(c.ResponsibleRoles, fromResponsibleRole),
+		Protocols: convert(c.Protocols, func(p types.Protocol) xmlProtocol {
+			return xmlProtocol{
+				UUID:  p.UUID,
+				Name:  p.Name,
+				Title: optionalLineMarkup(p.Title),
+				PortRanges: convert(p.PortRanges, func(r types.PortRange) xmlPortRange {
+					return xmlPortRange{Start: r.Start, End: r.End, Transport: r.Transport}
+				}),
+			}
+		}),
+		ControlImplementations: convert(c.ControlImplementations, fromControlImplementation),
+		Remarks:                optionalMultilineMarkup(c.Remarks),
+	}
+}
+
+func fromResponsibleRole(r types.ResponsibleRole) xmlResponsibleRole {
+	return xmlResponsibleRole{
+		RoleId:     r.RoleId,
+		Props:      convert(r.Props, fromProperty),
+		Links:      convert(r.Links, fromLink),
+		PartyUuids: r.PartyUuids,
+		Remarks:    optionalMultilineMarkup(r.Remarks),
+	}
+}
+
+func fromControlImplementation(c types.ControlImplementation) xmlControlImplementation {
+	return xmlControlImplementation{
+		UUID:                    c.UUID,
+		Source:                  c.Source,
+		Description:             multilineMarkup(c.Description),
+		Props:                   convert(c.Props, fromProperty),
+		Links:                   convert(c.Links, fromLink),
+		SetParameters:           convert(c.SetParameters, fromSetParameter),
+		ImplementedRequirements: convert(c.ImplementedRequirements, fromImplementedRequirement),
+	}
+}
+
+func fromSetParameter(s types.SetParameter) xmlSetParameter {
+	return xmlSetParameter{ParamId: s.ParamId, Values: s.Values, Remarks: optionalMultilineMarkup(s.Remarks)}
+}
+
+func fromImplementedRequirement(r types.ImplementedRequirement) xmlImplementedRequirement {
+	return xmlImplementedRequirement{
+		UUID:             r.UUID,
+		ControlId:        r.ControlId,
+		Description:      multilineMarkup(r.Description),
+		Props:            convert(r.Props, fromProperty),
+		Links:            convert(r.Links, fromLink),
+		SetParameters:    convert(r.SetParameters, fromSetParameter),
+		ResponsibleRoles: convert(r.ResponsibleRoles, fromResponsibleRole),
+		Statements: convert(r.Statements, func(s types.Statement) xmlStatement {
+			return xmlStatement{
+				StatementId:      s.StatementId,
+				UUID:             s.UUID,
+				Description:      multilineMarkup(s.Description),
+				Props:            convert(s.Props, fromProperty),
+				Links:            convert(s.Links, fromLink),
+				ResponsibleRoles: convert(s.ResponsibleRoles, fromResponsibleRole),
+				Remarks:          optionalMultilineMarkup(s.Remarks),
+			}
+		}),
+		Remarks: optionalMultilineMarkup(r.Remarks),
+	}
+}
+
+func fromCapability(c types.Capability) xmlCapability {
+	return xmlCapability{
+		UUID:        c.UUID,
+		Name:        c.Name,
+		Description: multilineMarkup(c.Description),
+		Props:       convert(c.Props, fromProperty),
+		Links:       convert(c.Links, fromLink),
+		IncorporatesComponents: convert(c.IncorporatesComponents, func(i types.IncorporatesComponent) xmlIncorporatesComponent {
+			return xmlIncorporatesComponent{ComponentUuid: i.ComponentUuid, Description: multilineMarkup(i.Description)}
+		}),
+		ControlImplementations: convert(c.ControlImplementations, fromControlImplementation),
+		Remarks:                optionalMultilineMarkup(c.Remarks),
+	}
+}
+
+func fromResource(r types.Resources) xmlResource {
+	resource := xmlResource{
+		UUID:        r.UUID,
+		Title:       optionalLineMarkup(r.Title),
+		Description: optionalMultilineMarkup(r.Description),
+		Props:       convert(r.Props, fromProperty),
+		DocumentIds: convert(r.DocumentIds, fromDocumentId),
+		Rlinks: convert(r.Rlinks, func(l types.Rlinks) xmlRlink {
+			return xmlRlink{
+				Href:      l.Href,
+				MediaType: l.MediaType,
+				Hashes: convert(l.Hashes, func(h types.Hash) xmlHash {
+					return xmlHash{Algorithm: h.Algorithm, Value: h.Value}
+				}),
+			}
+		}),
+		Remarks: optionalMultilineMarkup(r.Remarks),
+	}
+	if r.Citation != nil {
+		resource.Citation = &xmlCitation{
+			Text:  lineMarkup(r.Citation.Text),
+			Props: convert(r.Citation.Props, fromProperty),
+			Links: convert(r.Citation.Links, fromLink),
+		}
+	}
+	if r.Base64 != nil {
+		resource.Base64 = &xmlBase64{Filename: r.Base64.Filename, MediaType: r.Base64.MediaType, Value: r.Base64.Value}
+	}
+	return resource
+}
@@ -0,0 +1,272 @@
+// Package schema validates OSCAL component-definitions against the NIST OSCAL JSON schema of their version,
+// vendored under schemas/<major>.<minor>/ with `make update-schemas`.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed schemas
+var schemas embed.FS
+
+// schemaFile is the name of the complete OSCAL schema within each version's directory.
+const schemaFile = "oscal_complete_schema.json"
+
+var (
+	compiledMu sync.Mutex
+	compiled   = map[string]*jsonschema.Schema{}
+)
+
+// Issue is a single schema violation.
+type Issue struct {
+	// Path is the JSONPath of the offending value, such as $.component-definition.components[0].uuid
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// Error lists every schema violation found in a document.
+type Error struct {
+	// Version is the OSCAL version whose schema the document was validated against
+	Version string
+	Issues  []Issue
+}
+
+func (e *Error) Error() string {
+	lines := []string{fmt.Sprintf("%d OSCAL %s schema violation(s)", len(e.Issues), e.Version)}
+	for _, issue := range e.Issues {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Versions returns the OSCAL versions, as <major>.<minor>, that a schema is embedded for.
+func Versions() []string {
+	entries, _ := schemas.ReadDir("schemas")
+	versions := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// ValidateData validates a component-definition in any format, XML in its JSON form.
+func ValidateData(format oscal.Format, data []byte) error {
+	document, err := Decode(format, data)
+	if err != nil {
+		return err
+	}
+	return Validate(document)
+}
+
+// Decode decodes a component-definition in any format into the generic values decoded from JSON.
+func Decode(format oscal.Format, data []byte) (interface{}, error) {
+	var document interface{}
+
+	switch format {
+	case oscal.FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, err
+		}
+		return document, nil
+	case oscal.FormatYAML:
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		return fromYAML(document), nil
+	}
+
+	parsed, err := oscal.ParseComponentDocumentAs(format, data)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(oscal.FormatJSON, converted)
+}
+
+// ValidateDocument validates a parsed component-definition in its JSON form.
+func ValidateDocument(document types.OscalComponentDocument) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return ValidateData(oscal.FormatJSON, data)
+}
+
+// Validate validates a decoded component-definition against the schema of its oscal-version, returning an *Error.
+func Validate(document interface{}) error {
+	version, err := schemaVersion(document)
+	if err != nil {
+		return err
+	}
+	schema, err := compile(version)
+	if err != nil {
+		return err
+	}
+
+	err = schema.Validate(document)
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	return &Error{Version: version, Issues: issues(validationErr)}
+}
+
+// schemaVersion selects the embedded schema matching the major and minor version of a document's oscal-version.
+func schemaVersion(document interface{}) (string, error) {
+	versions := Versions()
+
+	root, _ := document.(map[string]interface{})
+	definition, _ := root["component-definition"].(map[string]interface{})
+	metadata, _ := definition["metadata"].(map[string]interface{})
+	oscalVersion, ok := metadata["oscal-version"].(string)
+	if !ok {
+		return versions[len(versions)-1], nil
+	}
+
+	parts := strings.SplitN(oscalVersion, ".", 3)
+	if len(parts) >= 2 {
+		for _, version := range versions {
+			if version == parts[0]+"."+parts[1] {
+				return version, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no schema for OSCAL version %q - supported versions are %s.x", oscalVersion, strings.Join(versions, ".x, "))
+}
+
+func compile(version string) (*jsonschema.Schema, error) {
+	compiledMu.Lock()
+	defer compiledMu.Unlock()
+
+	if schema, ok := compiled[version]; ok {
+		return schema, nil
+	}
+	data, err := schemas.ReadFile(path.Join("schemas", version, schemaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := componentBranch(data)
+	if err != nil {
+		return nil, fmt.Errorf("OSCAL %s schema: %w", version, err)
+	}
+
+	url := "schemas/" + version + "/" + schemaFile
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	schema, err := compiler.Compile(url + "#/oneOf/" + strconv.Itoa(branch))
+	if err != nil {
+		return nil, fmt.Errorf("compiling OSCAL %s schema: %w", version, err)
+	}
+	compiled[version] = schema
+	return schema, nil
+}
+
+// componentBranch finds the complete schema's oneOf branch for component-definitions, to validate against it alone.
+func componentBranch(data []byte) (int, error) {
+	var complete struct {
+		OneOf []struct {
+			Required []string `json:"required"`
+		} `json:"oneOf"`
+	}
+	if err := json.Unmarshal(data, &complete); err != nil {
+		return 0, err
+	}
+	for i, branch := range complete.OneOf {
+		if len(branch.Required) == 1 && branch.Required[0] == "component-definition" {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no component-definition model")
+}
+
+// issues flattens a validation error into its leaf causes, which name the specific values that are invalid.
+func issues(err *jsonschema.ValidationError) []Issue {
+	var (
+		result []Issue
+		seen   = map[Issue]bool{}
+		walk   func(*jsonschema.ValidationError)
+	)
+	walk = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			issue := Issue{Path: JSONPath(err.InstanceLocation), Message: err.Message}
+			if !seen[issue] {
+				seen[issue] = true
+				result = append(result, issue)
+			}
+			return
+		}
+		for _, cause := range err.Causes {
+			walk(cause)
+		}
+	}
+	walk(err)
+	return result
+}
+
+// JSONPath converts a JSON pointer into a JSONPath expression - /components/0/uuid becomes $.components[0].uuid.
+func JSONPath(pointer string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if _, err := strconv.Atoi(token); err == nil {
+			b.WriteString("[" + token + "]")
+			continue
+		}
+		b.WriteString("." + token)
+	}
+	return b.String()
+}
+
+// fromYAML converts a value decoded from YAML into the types decoded from JSON, which the validator requires.
+func fromYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = fromYAML(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = fromYAML(item)
+		}
+		return converted
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"os"
+	"testing"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"1.0", "1.1"}, Versions())
+	for _, version := range Versions() {
+		_, err := compile(version)
+		require.NoError(t, err, version)
+	}
+}
+
+func TestValidateData(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"jaeger-component-definition.json", "jaeger-component-definition.xml"} {
+		data, err := os.ReadFile("../../../testdata/input/" + name)
+		require.NoError(t, err)
+		require.NoError(t, ValidateData(oscal.DetectFormat(name, data), data), name)
+	}
+
+	invalid := `component-definition:
+  uuid: not-a-uuid
+  metadata:
+    title: Invalid
+    last-modified: 2021-10-19T12:00:00Z
+    version: 1
+    oscal-version: 1.1.2
+  components:
+  - uuid: 50EE9EB1-0DA4-411C-8771-AA1725B27E22
+    title: Missing type and description
+    color: blue
+`
+	err := ValidateData(oscal.FormatYAML, []byte(invalid))
+	var schemaErr *Error
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "1.1", schemaErr.Version)
+	require.ElementsMatch(t, []Issue{
+		{Path: "$.component-definition.uuid", Message: "does not match pattern '^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[45][0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}$'"},
+		{Path: "$.component-definition.metadata.version", Message: "expected string, but got number"},
+		{Path: "$.component-definition.components[0]", Message: "missing properties: 'type', 'description'"},
+		{Path: "$.component-definition.components[0]", Message: "additionalProperties 'color' not allowed"},
+	}, schemaErr.Issues)
+	require.ErrorContains(t, err, "4 OSCAL 1.1 schema violation(s)")
+
+	err = ValidateData(oscal.FormatJSON, []byte(`{"component-definition": {"uuid": "D69121EA-D112-41F8-8829-8638A173347A", "metadata": {"oscal-version": "2.0.0"}}}`))
+	require.ErrorContains(t, err, `no schema for OSCAL version "2.0.0" - supported versions are 1.0.x, 1.1.x`)
+
+	// Without an oscal-version the latest schema reports it missing
+	err = ValidateData(oscal.FormatJSON, []byte(`{"component-definition": {"uuid": "D69121EA-D112-41F8-8829-8638A173347A", "metadata": {}}}`))
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "1.1", schemaErr.Version)
+	require.ErrorContains(t, err, "'oscal-version'")
+}
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "$", JSONPath(""))
+	require.Equal(t, "$.component-definition.components[0].uuid", JSONPath("/component-definition/components/0/uuid"))
+	require.Equal(t, "$.a/b.c~d", JSONPath("/a~1b/c~0d"))
+}
@@ -0,0 +1,152 @@
+// Package semantic checks the cross references within an OSCAL component-definition that its schema cannot express.
+package semantic
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/schema"
+)
+
+// uuidPattern is the RFC 4122 version 4 or 5 UUID the OSCAL UUID datatype requires.
+var uuidPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[45][0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}$`)
+
+// definitions holds the identifiers a component-definition defines, keyed by identifier.
+type definitions struct {
+	roles      map[string]bool
+	parties    map[string]bool
+	locations  map[string]bool
+	components map[string]bool
+	resources  map[string]bool
+}
+
+// Check returns every semantic issue in a component-definition decoded by schema.Decode, in a stable order.
+func Check(document interface{}) []schema.Issue {
+	root, _ := document.(map[string]interface{})
+	definition, _ := root["component-definition"].(map[string]interface{})
+	metadata, _ := definition["metadata"].(map[string]interface{})
+	backMatter, _ := definition["back-matter"].(map[string]interface{})
+
+	defined := definitions{
+		roles:      collect(metadata["roles"], "id"),
+		parties:    collect(metadata["parties"], "uuid"),
+		locations:  collect(metadata["locations"], "uuid"),
+		components: collect(definition["components"], "uuid"),
+		resources:  collect(backMatter["resources"], "uuid"),
+	}
+
+	c := checker{defined: defined, seen: map[string]string{}}
+	c.walk("$", document)
+	return c.issues
+}
+
+type checker struct {
+	defined definitions
+	// seen maps each lower-cased uuid to the path it was first defined at
+	seen   map[string]string
+	issues []schema.Issue
+}
+
+func (c *checker) report(path, format string, args ...interface{}) {
+	c.issues = append(c.issues, schema.Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) walk(path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if nested(v[keys[i]]) != nested(v[keys[j]]) {
+				return !nested(v[keys[i]])
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			c.field(path+"."+key, key, v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			c.walk(path+"["+strconv.Itoa(i)+"]", item)
+		}
+	}
+}
+
+// field checks a single named value before descending into it.
+func (c *checker) field(path, key string, value interface{}) {
+	switch key {
+	case "uuid":
+		if uuid, ok := value.(string); ok {
+			c.uuid(path, uuid)
+		}
+	case "role-id":
+		if id, ok := value.(string); ok && !c.defined.roles[id] {
+			c.report(path, "role %q is not defined in metadata.roles", id)
+		}
+	case "party-uuids", "member-of-organizations":
+		c.references(path, value, c.defined.parties, "party", "metadata.parties")
+	case "location-uuids":
+		c.references(path, value, c.defined.locations, "location", "metadata.locations")
+	case "component-uuid":
+		if uuid, ok := value.(string); ok && !c.defined.components[strings.ToLower(uuid)] {
+			c.report(path, "component %s is not defined in components", uuid)
+		}
+	case "href":
+		if href, ok := value.(string); ok && strings.HasPrefix(href, "#") &&
+			!c.defined.resources[strings.ToLower(strings.TrimPrefix(href, "#"))] {
+			c.report(path, "link target %s is not a resource in back-matter", href)
+		}
+	}
+	c.walk(path, value)
+}
+
+func (c *checker) uuid(path, uuid string) {
+	if !uuidPattern.MatchString(uuid) {
+		c.report(path, "%q is not a valid UUID", uuid)
+		return
+	}
+	key := strings.ToLower(uuid)
+	if first, ok := c.seen[key]; ok {
+		c.report(path, "duplicate uuid %s, first defined at %s", uuid, first)
+		return
+	}
+	c.seen[key] = path
+}
+
+func (c *checker) references(path string, value interface{}, defined map[string]bool, kind, where string) {
+	items, _ := value.([]interface{})
+	for i, item := range items {
+		if uuid, ok := item.(string); ok && !defined[strings.ToLower(uuid)] {
+			c.report(path+"["+strconv.Itoa(i)+"]", "%s %s is not defined in %s", kind, uuid, where)
+		}
+	}
+}
+
+func nested(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// collect returns the values of a key across an array of objects.
+func collect(value interface{}, key string) map[string]bool {
+	result := map[string]bool{}
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		object, _ := item.(map[string]interface{})
+		if id, ok := object[key].(string); ok {
+			if key == "uuid" {
+				id = strings.ToLower(id)
+			}
+			result[id] = true
+		}
+	}
+	return result
+}
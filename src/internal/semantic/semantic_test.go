@@ -0,0 +1,98 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+	"github.com/defenseunicorns/component-generator/src/internal/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	header := `component-definition:
+  uuid: D69121EA-D112-41F8-8829-8638A173347A
+  metadata:
+    title: Test
+    last-modified: 2021-10-19T12:00:00Z
+    version: "1"
+    oscal-version: 1.1.2
+    roles:
+    - id: provider
+      title: Provider
+    parties:
+    - uuid: 6B8C8A39-4E4D-4A6D-9E0C-2A6AE0A3F3C1
+      type: organization
+      name: Defense Unicorns
+      links:
+      - href: "#1FC1D1D5-5E60-4D2A-8C2B-7D4C3E7D9A10"
+`
+	backMatter := `  back-matter:
+    resources:
+    - uuid: 1fc1d1d5-5e60-4d2a-8c2b-7d4c3e7d9a10
+      title: Logo
+`
+
+	tests := []struct {
+		name       string
+		components string
+		expected   []schema.Issue
+	}{
+		{
+			name: "valid",
+			components: `  components:
+  - uuid: 50EE9EB1-0DA4-411C-8771-AA1725B27E22
+    responsible-roles:
+    - role-id: provider
+      party-uuids:
+      - 6b8c8a39-4e4d-4a6d-9e0c-2a6ae0a3f3c1
+`,
+		},
+		{
+			name: "malformed and duplicate uuids",
+			components: `  components:
+  - uuid: not-a-uuid
+  - uuid: d69121ea-d112-41f8-8829-8638a173347a
+`,
+			expected: []schema.Issue{
+				{Path: "$.component-definition.components[0].uuid", Message: `"not-a-uuid" is not a valid UUID`},
+				{Path: "$.component-definition.components[1].uuid", Message: "duplicate uuid d69121ea-d112-41f8-8829-8638a173347a, first defined at $.component-definition.uuid"},
+			},
+		},
+		{
+			name: "unresolved references",
+			components: `  components:
+  - uuid: 50EE9EB1-0DA4-411C-8771-AA1725B27E22
+    links:
+    - href: "#A7BA800C-A432-4C4E-9AF6-7A4A1F4A6E0B"
+    responsible-roles:
+    - role-id: maintainer
+      party-uuids:
+      - 6B8C8A39-4E4D-4A6D-9E0C-2A6AE0A3F3C1
+      - 0B9C3C5D-7D6B-4F1E-8A43-1E0F1E2D3C4B
+  capabilities:
+  - uuid: 3F4A0C2E-6C1B-4B8E-9A2D-5E6F7A8B9C0D
+    incorporates-components:
+    - component-uuid: 0E5C1F6B-2D7A-4E3B-8C9D-1A2B3C4D5E6F
+`,
+			expected: []schema.Issue{
+				{Path: "$.component-definition.capabilities[0].incorporates-components[0].component-uuid", Message: "component 0E5C1F6B-2D7A-4E3B-8C9D-1A2B3C4D5E6F is not defined in components"},
+				{Path: "$.component-definition.components[0].links[0].href", Message: "link target #A7BA800C-A432-4C4E-9AF6-7A4A1F4A6E0B is not a resource in back-matter"},
+				{Path: "$.component-definition.components[0].responsible-roles[0].role-id", Message: `role "maintainer" is not defined in metadata.roles`},
+				{Path: "$.component-definition.components[0].responsible-roles[0].party-uuids[1]", Message: "party 0B9C3C5D-7D6B-4F1E-8A43-1E0F1E2D3C4B is not defined in metadata.parties"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			document, err := schema.Decode(oscal.FormatYAML, []byte(header+tt.components+backMatter))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, Check(document))
+		})
+	}
+}
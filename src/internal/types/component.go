@@ -1,22 +1,106 @@
 package types
 
+import "time"
+
 type ComponentsConfig struct {
 	Name          string    `json:"name" yaml:"name"`
+	Format        string    `json:"format,omitempty" yaml:"format,omitempty"`
 	Metadata      Metadata  `json:"metadata" yaml:"metadata"`
 	Components    Component `json:"components" yaml:"components"`
+	Auth          []Auth    `json:"auth,omitempty" yaml:"auth,omitempty"`
 	BaseDirectory string    `json:"base-directory" yaml:"base-directory"`
+	// Deterministic derives the uuid and last-modified from the inputs, in UUIDNamespace when set
+	Deterministic bool   `json:"deterministic,omitempty" yaml:"deterministic,omitempty"`
+	UUIDNamespace string `json:"uuid-namespace,omitempty" yaml:"uuid-namespace,omitempty"`
+	// Canonical sorts the document by stable keys and normalizes whitespace
+	Canonical bool `json:"canonical,omitempty" yaml:"canonical,omitempty"`
+	// Duplicates is one of error (the default), keep-first, keep-last or deep-merge
+	Duplicates string `json:"duplicates,omitempty" yaml:"duplicates,omitempty"`
+	// Imports is preserve (the default) or resolve
+	Imports string `json:"imports,omitempty" yaml:"imports,omitempty"`
+	// ImportDepth limits how many levels of imports are resolved, defaulting to 8
+	ImportDepth int `json:"import-depth,omitempty" yaml:"import-depth,omitempty"`
+	// CacheDirectory, Offline, Concurrency, Timeout and Retries are runtime settings supplied on the command line
+	CacheDirectory string        `json:"-" yaml:"-"`
+	Offline        bool          `json:"-" yaml:"-"`
+	Concurrency    int           `json:"-" yaml:"-"`
+	Timeout        time.Duration `json:"-" yaml:"-"`
+	Retries        int           `json:"-" yaml:"-"`
 }
 
 type Component struct {
 	Locals  []Local  `json:"local" yaml:"local"`
 	Remotes []Remote `json:"remote" yaml:"remote"`
+	URLs    []URL    `json:"url,omitempty" yaml:"url,omitempty"`
+	OCI     []OCI    `json:"oci,omitempty" yaml:"oci,omitempty"`
+	Zarf    []Zarf   `json:"zarf,omitempty" yaml:"zarf,omitempty"`
+	Helm    []Helm   `json:"helm,omitempty" yaml:"helm,omitempty"`
+	Sources []Source `json:"source,omitempty" yaml:"source,omitempty"`
 }
 
+// Local is a component-definition file, glob pattern or directory, optionally pinned by Hash.
 type Local struct {
-	Name string `json:"name" yaml:"name"`
+	Name    string   `json:"name" yaml:"name"`
+	Hash    string   `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
+// Remote is a component-definition in a git repository, optionally pinned by Hash.
 type Remote struct {
 	Git  string `json:"git" yaml:"git"`
 	Path string `json:"path" yaml:"path"`
+	Hash string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// URL is a component-definition downloaded over http(s), pinned by Checksum.
+type URL struct {
+	URL      string `json:"url" yaml:"url"`
+	Checksum string `json:"checksum" yaml:"checksum"`
+}
+
+// OCI is a component-definition layer of an OCI artifact in a registry, or in a layout directory when Layout is set.
+type OCI struct {
+	Ref       string `json:"ref" yaml:"ref"`
+	Layout    string `json:"layout,omitempty" yaml:"layout,omitempty"`
+	MediaType string `json:"media-type,omitempty" yaml:"media-type,omitempty"`
+}
+
+// UnmarshalYAML allows an OCI entry to be written as a bare image reference.
+func (o *OCI) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var ref string
+	if err := unmarshal(&ref); err == nil {
+		*o = OCI{Ref: ref}
+		return nil
+	}
+
+	type plain OCI
+	return unmarshal((*plain)(o))
+}
+
+// Zarf is a Zarf package whose component-definitions, optionally only those matching Path, are extracted.
+type Zarf struct {
+	Package string `json:"package" yaml:"package"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Helm is a component-definition in a local chart or, when Repo is set, a chart from a chart repository.
+type Helm struct {
+	Chart   string `json:"chart" yaml:"chart"`
+	Repo    string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Source is a component-definition retrieved by the fetcher registered for the scheme of its URI.
+type Source struct {
+	URI string `json:"uri" yaml:"uri"`
+}
+
+// Auth names the environment variables holding the credential for a host.
+type Auth struct {
+	Host        string `json:"host" yaml:"host"`
+	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
+	TokenEnv    string `json:"token-env,omitempty" yaml:"token-env,omitempty"`
+	UsernameEnv string `json:"username-env,omitempty" yaml:"username-env,omitempty"`
+	PasswordEnv string `json:"password-env,omitempty" yaml:"password-env,omitempty"`
 }
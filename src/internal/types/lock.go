@@ -0,0 +1,14 @@
+package types
+
+// Lockfile records what every source of a components config resolved to, so that later runs can detect drift.
+type Lockfile struct {
+	Sources []LockedSource `json:"sources" yaml:"sources"`
+}
+
+// LockedSource is a single fetched document. Name is only set when it differs from Source.
+type LockedSource struct {
+	Source   string `json:"source" yaml:"source"`
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Resolved string `json:"resolved,omitempty" yaml:"resolved,omitempty"`
+	Sha256   string `json:"sha256" yaml:"sha256"`
+}
@@ -90,9 +90,9 @@ type Resources struct {
 	Title       string       `json:"title,omitempty" yaml:"title,omitempty"`
 	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
 	DocumentIds []DocumentId `json:"document-ids,omitempty" yaml:"document-ids,omitempty"`
-	Citation    []Citation   `json:"citation,omitempty" yaml:"citation,omitempty"`
+	Citation    *Citation    `json:"citation,omitempty" yaml:"citation,omitempty"`
 	Rlinks      []Rlinks     `json:"rlinks,omitempty" yaml:"rlinks,omitempty"`
-	Base64      []Base64     `json:"base64,omitempty" yaml:"base64,omitempty"`
+	Base64      *Base64      `json:"base64,omitempty" yaml:"base64,omitempty"`
 	Props       []Property   `json:"props,omitempty" yaml:"props,omitempty"`
 }
 type Property struct {
@@ -0,0 +1,88 @@
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/google/uuid"
+)
+
+// mergeBackMatter collects the back-matter resources of every document once, giving a resource whose uuid is taken
+// a new one, derived from its content, that the #uuid hrefs of its document follow.
+func mergeBackMatter(documents []types.OscalComponentDocument) []types.Resources {
+	var (
+		resources = []types.Resources{}
+		// variants maps each lower-cased source uuid to the indexes of the resources collected for it
+		variants = map[string][]int{}
+	)
+	for d := range documents {
+		definition := &documents[d].ComponentDefinition
+		remapped := map[string]string{}
+
+		for _, resource := range definition.BackMatter.Resources {
+			key := strings.ToLower(resource.UUID)
+			index := -1
+			for _, i := range variants[key] {
+				if sameResource(resources[i], resource) {
+					index = i
+					break
+				}
+			}
+
+			switch {
+			case index >= 0:
+				if resources[index].UUID != resource.UUID {
+					remapped[key] = resources[index].UUID
+				}
+				continue
+			case len(variants[key]) > 0:
+				content, _ := json.Marshal(resource)
+				resource.UUID = uuid.NewSHA1(Namespace, content).String()
+				remapped[key] = resource.UUID
+			}
+			variants[key] = append(variants[key], len(resources))
+			resources = append(resources, resource)
+		}
+
+		if len(remapped) > 0 {
+			rewriteHrefs(reflect.ValueOf(&definition.ImportComponentDefinitions).Elem(), remapped)
+			rewriteHrefs(reflect.ValueOf(&definition.Components).Elem(), remapped)
+			rewriteHrefs(reflect.ValueOf(&definition.Capabilities).Elem(), remapped)
+		}
+	}
+	return resources
+}
+
+// sameResource reports whether two resources have the same content, ignoring the case of their uuids.
+func sameResource(a, b types.Resources) bool {
+	b.UUID = a.UUID
+	return reflect.DeepEqual(a, b)
+}
+
+// rewriteHrefs rewrites every #uuid Href reachable from a value to follow remapped, keyed by lower-cased uuid.
+func rewriteHrefs(v reflect.Value, remapped map[string]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			rewriteHrefs(v.Elem(), remapped)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			rewriteHrefs(v.Index(i), remapped)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if v.Type().Field(i).Name == "Href" && field.Kind() == reflect.String {
+				href := field.String()
+				if to, ok := remapped[strings.ToLower(strings.TrimPrefix(href, "#"))]; ok && strings.HasPrefix(href, "#") {
+					field.SetString("#" + to)
+				}
+				continue
+			}
+			rewriteHrefs(field, remapped)
+		}
+	}
+}
@@ -1,61 +1,151 @@
 package component
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/defenseunicorns/component-generator/src/internal/oscal"
 	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v2"
 )
 
+// BuildOscalDocument fetches every source in the config and aggregates them into a single OSCAL component-definition.
 func BuildOscalDocument(config types.ComponentsConfig) (string, types.OscalComponentDocument, error) {
-	var (
-		backMatterResources = []types.Resources{}
-		components          = []types.DefinedComponent{}
-		rfc3339Time         = time.Now().Format(time.RFC3339)
-		documents           = []types.OscalComponentDocument{}
-	)
+	documents, err := FetchDocuments(config)
+	if err != nil {
+		return "", types.OscalComponentDocument{}, err
+	}
+	return AggregateDocuments(config, documents)
+}
 
-	for _, local := range config.Components.Locals {
-		document, err := oscal.GetOscalComponentFromLocal(config.BaseDirectory + local.Name)
-		if err != nil {
-			return "", types.OscalComponentDocument{}, err
-		}
-		documents = append(documents, document)
+// FetchDocuments fetches the sources in the config concurrently and returns their documents in declared order.
+func FetchDocuments(config types.ComponentsConfig) ([]source.Document, error) {
+	sources, err := configSources(config.Components)
+	if err != nil {
+		return nil, err
+	}
+	imports, err := ParseImportPolicy(config.Imports)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, remote := range config.Components.Remotes {
+	opts := source.Options{
+		BaseDirectory:  config.BaseDirectory,
+		CacheDirectory: config.CacheDirectory,
+		Offline:        config.Offline,
+		Auth:           config.Auth,
+		Timeout:        config.Timeout,
+		Retries:        config.Retries,
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-		if git := remote.Git; git != "" {
-			if !strings.Contains(git, "@") {
-				return "", types.OscalComponentDocument{}, fmt.Errorf("remote git URL must specify a git ref using the following syntax: 'https://github.com/<org>/<repo>@<git ref>'")
+	var (
+		fetched = make([][]source.Document, len(sources))
+		errs    = make([]error, len(sources))
+		limit   = make(chan struct{}, concurrency)
+		wg      sync.WaitGroup
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int, src source.Source) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			fetched[i], errs[i] = source.Fetch(src, opts)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("failed to fetch %v: %w", src, errs[i])
 			}
-			split := strings.Split(git, "@")
-			document, err := oscal.GetOscalComponentDocumentFromRepo(split[0], split[1], remote.Path)
-			if err != nil {
-				return "", types.OscalComponentDocument{}, fmt.Errorf("no OSCAL document was found for %v", git)
+		}(i, src)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	documents := []source.Document{}
+	read := map[string]bool{}
+	for _, docs := range fetched {
+		for _, doc := range docs {
+			// Overlapping local entries, such as a glob and a file it matches, contribute each file once
+			if doc.Source.Scheme == source.FileScheme {
+				path, err := filepath.Abs(localDocumentPath(config.BaseDirectory, doc.Name))
+				if err != nil {
+					return nil, err
+				}
+				if read[path] {
+					continue
+				}
+				read[path] = true
 			}
-			documents = append(documents, document)
+			documents = append(documents, doc)
 		}
+	}
+	if imports == ImportResolve {
+		return resolveImports(documents, opts, config.ImportDepth)
+	}
+	return documents, nil
+}
 
+// localDocumentPath returns the path of a local document, whose name is relative to the base directory.
+func localDocumentPath(baseDirectory, name string) string {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(baseDirectory, name)
+}
+
+// AggregateDocuments aggregates previously fetched documents into a single OSCAL component-definition.
+func AggregateDocuments(config types.ComponentsConfig, fetched []source.Document) (string, types.OscalComponentDocument, error) {
+	rfc3339Time := time.Now().Format(time.RFC3339)
+
+	policy, err := ParseDuplicatePolicy(config.Duplicates)
+	if err != nil {
+		return "", types.OscalComponentDocument{}, err
+	}
+	documents, err := parseDocuments(fetched)
+	if err != nil {
+		return "", types.OscalComponentDocument{}, err
 	}
+	recordResolution(fetched, documents)
 
-	// Collect the components and back-matter fields from component definitions
-	for _, doc := range documents {
-		components = append(components, doc.ComponentDefinition.Components...)
-		backMatterResources = append(backMatterResources, doc.ComponentDefinition.BackMatter.Resources...)
+	// The back-matter is merged first, as it may rewrite the links of the rest
+	backMatterResources := mergeBackMatter(documents)
+	components, componentUUIDs, componentCollisions := deduplicate(policy, groupComponents(fetched, documents))
+	capabilities, _, capabilityCollisions := deduplicate(policy, groupCapabilities(fetched, documents))
+	if collisions := append(componentCollisions, capabilityCollisions...); len(collisions) > 0 {
+		return "", types.OscalComponentDocument{}, collisionError(collisions)
 	}
+	incorporateComponents(capabilities, componentUUIDs)
 
-	config.Metadata.LastModified = rfc3339Time
+	imports, err := aggregateImports(config, fetched, documents)
+	if err != nil {
+		return "", types.OscalComponentDocument{}, err
+	}
+
+	if !config.Deterministic {
+		config.Metadata.LastModified = rfc3339Time
+	} else if config.Metadata.LastModified == "" {
+		config.Metadata.LastModified = latestModified(documents)
+	}
 	// Populate the aggregated component definition
 	aggregateOscalDocument := types.OscalComponentDocument{
 		ComponentDefinition: types.ComponentDefinition{
-			UUID:       uuid.NewString(),
-			Components: components,
+			ImportComponentDefinitions: imports,
+			Components:                 components,
+			Capabilities:               capabilities,
 			BackMatter: types.BackMatter{
 				Resources: backMatterResources,
 			},
@@ -63,25 +153,92 @@ func BuildOscalDocument(config types.ComponentsConfig) (string, types.OscalCompo
 		},
 	}
 
-	yamlDocBytes, err := yaml.Marshal(aggregateOscalDocument)
+	if config.Canonical {
+		oscal.Canonicalize(&aggregateOscalDocument)
+	}
+	if config.Deterministic {
+		id, err := DeterministicUUID(config, aggregateOscalDocument)
+		if err != nil {
+			return "", aggregateOscalDocument, err
+		}
+		aggregateOscalDocument.ComponentDefinition.UUID = id
+	} else {
+		aggregateOscalDocument.ComponentDefinition.UUID = uuid.NewString()
+	}
+
+	format, err := OutputFormat(config)
 	if err != nil {
 		return "", aggregateOscalDocument, err
 	}
-	return string(yamlDocBytes), aggregateOscalDocument, nil
+	docBytes, err := oscal.MarshalComponentDocument(format, aggregateOscalDocument)
+	if err != nil {
+		return "", aggregateOscalDocument, err
+	}
+	return string(docBytes), aggregateOscalDocument, nil
+}
+
+// PropertyNamespace is the ns of the props the generator adds to aggregated components.
+const PropertyNamespace = "https://github.com/defenseunicorns/component-generator"
+
+// recordResolution adds resolved-source and resolved-commit props to the components of documents fetched from git.
+func recordResolution(fetched []source.Document, documents []types.OscalComponentDocument) {
+	for i, doc := range fetched {
+		if doc.Source.Scheme != source.GitScheme || doc.Resolved == "" {
+			continue
+		}
+		components := documents[i].ComponentDefinition.Components
+		for c := range components {
+			components[c].Props = append(components[c].Props,
+				types.Property{Name: "resolved-source", Ns: PropertyNamespace, Value: doc.Name},
+				types.Property{Name: "resolved-commit", Ns: PropertyNamespace, Value: doc.Resolved},
+			)
+		}
+	}
+}
+
+// parseDocuments parses previously fetched documents in whichever format each is written in.
+func parseDocuments(fetched []source.Document) ([]types.OscalComponentDocument, error) {
+	documents := make([]types.OscalComponentDocument, 0, len(fetched))
+	for _, raw := range fetched {
+		document, err := oscal.ParseComponentDocumentAs(oscal.DetectFormat(raw.Name, raw.Content), raw.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %v: %w", raw.Name, err)
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// OutputFormat returns the config's format, or the one implied by the extension of its name, defaulting to YAML.
+func OutputFormat(config types.ComponentsConfig) (oscal.Format, error) {
+	if config.Format != "" {
+		return oscal.ParseFormat(config.Format)
+	}
+	switch strings.ToLower(filepath.Ext(config.Name)) {
+	case ".json":
+		return oscal.FormatJSON, nil
+	case ".xml":
+		return oscal.FormatXML, nil
+	}
+	return oscal.FormatYAML, nil
 }
 
 // DiffComponentObjects compares two OSCAL component definitions.
 // If they're the same, it returns true.
 // If they're different, it returns false.
 func DiffComponentObjects(origObj types.OscalComponentDocument, newObj types.OscalComponentDocument) bool {
-	// Compare the metadata structs and the list of components
+	// Compare the metadata structs and everything that is aggregated
 	// in-scope set LastModified to empty string to remove it from consideration
 	origObj.ComponentDefinition.Metadata.LastModified = ""
 	newObj.ComponentDefinition.Metadata.LastModified = ""
+	orig, updated := origObj.ComponentDefinition, newObj.ComponentDefinition
 
-	metaCompare := reflect.DeepEqual(origObj.ComponentDefinition.Metadata, newObj.ComponentDefinition.Metadata)
+	metaCompare := reflect.DeepEqual(orig.Metadata, updated.Metadata)
 
-	childCompare := reflect.DeepEqual(origObj.ComponentDefinition.Components, newObj.ComponentDefinition.Components)
+	childCompare := reflect.DeepEqual(orig.Components, updated.Components) &&
+		reflect.DeepEqual(orig.Capabilities, updated.Capabilities) &&
+		reflect.DeepEqual(orig.ImportComponentDefinitions, updated.ImportComponentDefinitions) &&
+		reflect.DeepEqual(orig.BackMatter, updated.BackMatter)
 
 	return childCompare && metaCompare
 }
@@ -1,12 +1,27 @@
 package component
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
 	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 )
@@ -114,6 +129,48 @@ func TestDiffComponentObjects(t *testing.T) {
 			},
 			expectedResult: true, // Changes to 'metadata.LastModified' were made, which shouldn't be detected, so the result should be true
 		},
+		{
+			name: "Changes in capabilities",
+			origObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					Capabilities: []types.Capability{{UUID: "1", Name: "Observability"}},
+				},
+			},
+			newObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					Capabilities: []types.Capability{{UUID: "1", Name: "Tracing"}},
+				},
+			},
+			expectedResult: false, // Changes to capabilities were made, so the result should be false
+		},
+		{
+			name: "Changes in imports",
+			origObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					ImportComponentDefinitions: []types.ImportComponentDefinition{{Href: "istio.yaml"}},
+				},
+			},
+			newObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					ImportComponentDefinitions: []types.ImportComponentDefinition{{Href: "../istio.yaml"}},
+				},
+			},
+			expectedResult: false, // Changes to imports were made, so the result should be false
+		},
+		{
+			name: "Changes in back-matter",
+			origObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					BackMatter: types.BackMatter{Resources: []types.Resources{{UUID: "1"}}},
+				},
+			},
+			newObj: types.OscalComponentDocument{
+				ComponentDefinition: types.ComponentDefinition{
+					BackMatter: types.BackMatter{Resources: []types.Resources{{UUID: "2"}}},
+				},
+			},
+			expectedResult: false, // Changes to back-matter were made, so the result should be false
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -128,6 +185,7 @@ func TestDiffComponentObjects(t *testing.T) {
 // TestBuildOscalDocumentWithValidConfigFile tests that OSCAL component definition files are generated correctly using a valid config file.
 func TestBuildOscalDocumentWithValidConfigFile(t *testing.T) {
 	t.Parallel()
+	requireNetwork(t, "github.com:443", "repo1.dso.mil:443")
 
 	// Read in the valid config file as test data
 	configFilePath := "../../../testdata/input/valid-components.yaml"
@@ -148,6 +206,18 @@ func TestBuildOscalDocumentWithValidConfigFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// The commits the remotes resolve to are not pinned by the expected output
+	for i := range actualComponentDefinition.ComponentDefinition.Components {
+		component := &actualComponentDefinition.ComponentDefinition.Components[i]
+		var props []types.Property
+		for _, prop := range component.Props {
+			if prop.Ns != PropertyNamespace {
+				props = append(props, prop)
+			}
+		}
+		component.Props = props
+	}
+
 	// Perform a diff of the expected output and actual output
 	match := DiffComponentObjects(expectedComponentDefinition, actualComponentDefinition)
 
@@ -158,6 +228,80 @@ func TestBuildOscalDocumentWithValidConfigFile(t *testing.T) {
 }
 
 // TestBuildOscalDocumentWithInvalidConfigFile verifies that the BuildOscalDocument() function returns an error when an invalid config file is provided.
+// TestBuildOscalDocumentFromRemotes aggregates every kind of remote source from servers and a repository local to the
+// test, so that fetching remotes end to end is tested without the network.
+func TestBuildOscalDocumentFromRemotes(t *testing.T) {
+	t.Parallel()
+
+	definitions := map[string]string{}
+	for _, title := range []string{"Kiali", "Istio", "Jaeger", "Tempo"} {
+		sum := sha256.Sum256([]byte(title))
+		id := uuid.NewSHA1(uuid.NameSpaceOID, sum[:]).String()
+		definitions[title] = definitionYAML(title, "  components:\n  - uuid: "+id+"\n    type: software\n    title: "+title+"\n    description: "+title+"\n")
+	}
+
+	repo := gitRepository(t, map[string]string{"oscal-component.yaml": definitions["Kiali"]}, "1.60.0-bb.2")
+
+	chart := tarGz(t, map[string]string{
+		"tempo/Chart.yaml":           "apiVersion: v2\nname: tempo\nversion: 1.7.0-bb.1\n",
+		"tempo/oscal-component.yaml": definitions["Tempo"],
+	})
+	chartSum := sha256.Sum256(chart)
+	index := "apiVersion: v1\nentries:\n  tempo:\n  - version: 1.7.0-bb.1\n    urls: [tempo-1.7.0-bb.1.tgz]\n    digest: " + hex.EncodeToString(chartSum[:]) + "\n"
+
+	layer := []byte(definitions["Jaeger"])
+	layerDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(layer))
+	manifest := []byte(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[{"mediaType":"application/vnd.oscal.component-definition+yaml","digest":"` + layerDigest + `","size":` + strconv.Itoa(len(layer)) + `}]}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := map[string][]byte{
+			"/oscal/istio.yaml":                   []byte(definitions["Istio"]),
+			"/v2/org/jaeger/manifests/1.0.0":      manifest,
+			"/v2/org/jaeger/blobs/" + layerDigest: layer,
+			"/charts/index.yaml":                  []byte(index),
+			"/charts/tempo-1.7.0-bb.1.tgz":        chart,
+		}[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	config := types.ComponentsConfig{Name: filepath.Join(t.TempDir(), "aggregate.yaml"), Metadata: types.Metadata{Title: "Remotes", Version: "1.0.0"}}
+	config.Components.Remotes = []types.Remote{{Git: repo + "//oscal-component.yaml@~1.60.0"}}
+	config.Components.URLs = []types.URL{{URL: server.URL + "/oscal/istio.yaml", Checksum: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(definitions["Istio"])))}}
+	config.Components.OCI = []types.OCI{{Ref: strings.TrimPrefix(server.URL, "http://") + "/org/jaeger:1.0.0"}}
+	config.Components.Helm = []types.Helm{{Chart: "tempo", Repo: server.URL + "/charts", Version: "1.7.0-bb.1"}}
+
+	_, document, err := BuildOscalDocument(config)
+	require.NoError(t, err)
+	titles := []string{}
+	for _, component := range document.ComponentDefinition.Components {
+		titles = append(titles, component.Title)
+	}
+	require.Equal(t, []string{"Kiali", "Istio", "Jaeger", "Tempo"}, titles)
+	require.Contains(t, document.ComponentDefinition.Components[0].Props, types.Property{Name: "resolved-source", Ns: PropertyNamespace, Value: repo + "//oscal-component.yaml@1.60.0-bb.2"})
+}
+
+// tarGz returns a gzipped tarball of files keyed by their slash-separated path.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
 func TestBuildOscalDocumentWithInvalidConfigFile(t *testing.T) {
 	t.Parallel()
 
@@ -174,6 +318,22 @@ func TestBuildOscalDocumentWithInvalidConfigFile(t *testing.T) {
 	require.ErrorContains(t, err, "remote git URL must specify a git ref")
 }
 
+// requireNetwork skips a test with -short or when any of the remotes cannot be reached.
+func requireNetwork(t *testing.T, addresses ...string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping test that fetches remotes in short mode")
+	}
+	for _, address := range addresses {
+		conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+		if err != nil {
+			t.Skipf("skipping test that fetches remotes - %s is unreachable: %v", address, err)
+		}
+		conn.Close()
+	}
+}
+
 func readConfigFile(t *testing.T, filePath string) (configFile types.ComponentsConfig, err error) {
 	t.Helper()
 
@@ -207,3 +367,771 @@ func readTestComponentDefinitionFile(t *testing.T) (componentDefinition types.Os
 
 	return componentDefinition, err
 }
+
+// definitionYAML returns a component-definition in YAML titled title with body beneath component-definition.
+func definitionYAML(title, body string) string {
+	return "component-definition:\n  uuid: 8C5E1B6A-3D2F-4A7C-9E0B-1F2A3B4C5D6E\n  metadata:\n    title: " + title +
+		"\n    last-modified: 2021-10-19T12:00:00Z\n    version: 1.0.0\n    oscal-version: 1.0.4\n" + body
+}
+
+// importsYAML returns the import-component-definitions of a definitionYAML body.
+func importsYAML(hrefs ...string) string {
+	body := "  import-component-definitions:\n"
+	for _, href := range hrefs {
+		body += "  - href: '" + href + "'\n"
+	}
+	return body
+}
+
+// writeFiles writes files, keyed by their slash-separated path, beneath a new temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+// gitRepository commits files to a new bare repository, tags the commit and returns the repository's file URL.
+func gitRepository(t *testing.T, files map[string]string, tag string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	work, bare := writeFiles(t, files), t.TempDir()
+	for _, args := range [][]string{
+		{"-C", work, "init", "--quiet"},
+		{"-C", work, "add", "."},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "test"},
+		{"-C", work, "tag", tag},
+		{"clone", "--quiet", "--bare", work, bare},
+	} {
+		output, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	return "file://" + filepath.ToSlash(bare)
+}
+
+// TestVerifyLockfile checks that drift between a lockfile and the documents fetched by the current run is reported.
+func TestVerifyLockfile(t *testing.T) {
+	t.Parallel()
+
+	remote := source.Source{Scheme: source.GitScheme, Location: "https://example.com/repo.git", Path: "oscal-component.yaml", Ref: "v1.0.0"}
+	local := source.Source{Scheme: source.FileScheme, Location: "component.yaml"}
+
+	lock := NewLockfile([]source.Document{
+		{Source: remote, Name: remote.String(), Content: []byte("remote"), Resolved: "aaaa"},
+		{Source: local, Name: "component.yaml", Content: []byte("local")},
+	})
+	require.Equal(t, "https://example.com/repo.git//oscal-component.yaml@v1.0.0", lock.Sources[0].Source)
+	require.Empty(t, lock.Sources[0].Name)
+	require.NoError(t, VerifyLockfile(lock, lock))
+
+	moved := NewLockfile([]source.Document{
+		{Source: remote, Name: remote.String(), Content: []byte("remote changed"), Resolved: "bbbb"},
+		{Source: source.Source{Scheme: source.FileScheme, Location: "new.yaml"}, Name: "new.yaml", Content: []byte("new")},
+	})
+	err := VerifyLockfile(lock, moved)
+	require.ErrorContains(t, err, `resolved to "bbbb", lockfile has "aaaa"`)
+	require.ErrorContains(t, err, "has sha256")
+	require.ErrorContains(t, err, "new.yaml is not in the lockfile")
+	require.ErrorContains(t, err, "component.yaml is in the lockfile but no longer fetched")
+}
+
+// TestFetchDocumentsVerifiesHash checks that a source's content is verified against its declared hash before use.
+func TestFetchDocumentsVerifiesHash(t *testing.T) {
+	t.Parallel()
+
+	config := types.ComponentsConfig{BaseDirectory: "../../../testdata/input/"}
+	config.Components.Locals = []types.Local{{
+		Name: "jaeger-component-definition.yaml",
+		Hash: "sha256:7b1bf3e6d0b3a82c9d38e4b8e2a3f62bd4a0ef6ce4c9fa1b7c0c1b0a6e9d1234",
+	}}
+
+	_, err := FetchDocuments(config)
+	require.ErrorContains(t, err, "integrity check failed for jaeger-component-definition.yaml: digest mismatch")
+
+	config.Components.Locals[0].Hash = "sha256:39b0b1c4365f6a0a0fb6b15244cc7f99ea41b8a0053654fcef1905abde5f5767"
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+}
+
+// TestFetchDocumentsOverlappingLocals checks that a file matched by several local entries is read once.
+func TestFetchDocumentsOverlappingLocals(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"components/istio.yaml": definitionYAML("Istio", ""),
+		"components/kiali.yaml": definitionYAML("Kiali", ""),
+	})
+	config := types.ComponentsConfig{BaseDirectory: dir, Duplicates: "error"}
+	config.Components.Locals = []types.Local{
+		{Name: "components/kiali.yaml"},
+		{Name: "components/*.yaml"},
+		{Name: "components"},
+		{Name: filepath.Join(dir, "components", "istio.yaml")},
+	}
+
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	names := []string{}
+	for _, doc := range documents {
+		names = append(names, doc.Name)
+	}
+	require.Equal(t, []string{"components/kiali.yaml", "components/istio.yaml"}, names)
+
+	_, _, err = AggregateDocuments(config, documents)
+	require.NoError(t, err)
+}
+
+// TestFetchDocumentsConcurrently checks that documents keep the declared order and every failed source is reported.
+func TestFetchDocumentsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	scheme := "test-fetch-concurrently"
+	source.Register(scheme, source.FetcherFunc(func(src source.Source, _ source.Options) ([]source.Document, error) {
+		delay, _ := strconv.Atoi(strings.TrimPrefix(src.Location, scheme+"://"))
+		if delay < 0 {
+			return nil, fmt.Errorf("unavailable")
+		}
+		// Later sources finish first
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+		return []source.Document{{Source: src, Name: src.Location}}, nil
+	}))
+	t.Cleanup(func() { source.Register(scheme, nil) })
+
+	config := types.ComponentsConfig{Concurrency: 4}
+	for _, delay := range []string{"40", "30", "20", "10", "0"} {
+		config.Components.Sources = append(config.Components.Sources, types.Source{URI: scheme + "://" + delay})
+	}
+
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	names := []string{}
+	for _, doc := range documents {
+		names = append(names, strings.TrimPrefix(doc.Name, scheme+"://"))
+	}
+	require.Equal(t, []string{"40", "30", "20", "10", "0"}, names)
+
+	config.Components.Sources = append(config.Components.Sources,
+		types.Source{URI: scheme + "://-1"},
+		types.Source{URI: scheme + "://-2"},
+	)
+	_, err = FetchDocuments(config)
+	require.ErrorContains(t, err, "failed to fetch "+scheme+"://-1: unavailable")
+	require.ErrorContains(t, err, "failed to fetch "+scheme+"://-2: unavailable")
+}
+
+// TestFetchDocumentsFromSourceURIs checks that source URIs reach their fetchers parsed rather than as the full URI.
+func TestFetchDocumentsFromSourceURIs(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"charts/kiali/Chart.yaml":           "apiVersion: v2\nname: kiali\nversion: 1.60.0-bb.2\n",
+		"charts/kiali/oscal-component.yaml": definitionYAML("Kiali", ""),
+		"components/jaeger.yaml":            definitionYAML("Jaeger", ""),
+	})
+	config := types.ComponentsConfig{BaseDirectory: dir}
+	config.Components.Sources = []types.Source{
+		{URI: "helm:charts/kiali@1.60.0-bb.2"},
+		{URI: "file://" + filepath.ToSlash(filepath.Join(dir, "components", "jaeger.yaml"))},
+	}
+
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+	require.Equal(t, source.Source{Scheme: source.HelmScheme, Location: filepath.FromSlash("charts/kiali"), Ref: "1.60.0-bb.2"}, documents[0].Source)
+	require.Contains(t, string(documents[0].Content), "title: Kiali")
+	require.Contains(t, string(documents[1].Content), "title: Jaeger")
+
+	config.Components.Sources = []types.Source{{URI: "helm:charts/kiali@1.59.0"}}
+	_, err = FetchDocuments(config)
+	require.ErrorContains(t, err, "chart kiali is version 1.60.0-bb.2, expected 1.59.0")
+}
+
+func TestRecordResolution(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.yaml")
+	require.NoError(t, err)
+	remote := source.Source{Scheme: source.GitScheme, Location: "https://example.com/jaeger.git", Path: "oscal-component.yaml", Ref: "~1.60.0"}
+	fetched := []source.Document{{
+		Source:   remote,
+		Name:     "https://example.com/jaeger.git//oscal-component.yaml@1.60.2",
+		Content:  content,
+		Resolved: "0123456789abcdef0123456789abcdef01234567",
+	}}
+
+	_, document, err := AggregateDocuments(types.ComponentsConfig{Name: "aggregate.yaml"}, fetched)
+	require.NoError(t, err)
+	props := document.ComponentDefinition.Components[0].Props
+	require.Contains(t, props, types.Property{Name: "resolved-source", Ns: PropertyNamespace, Value: "https://example.com/jaeger.git//oscal-component.yaml@1.60.2"})
+	require.Contains(t, props, types.Property{Name: "resolved-commit", Ns: PropertyNamespace, Value: "0123456789abcdef0123456789abcdef01234567"})
+}
+
+func TestBuildOscalDocumentWithMixedFormats(t *testing.T) {
+	t.Parallel()
+
+	config := types.ComponentsConfig{BaseDirectory: "../../../testdata/input/"}
+	config.Components.Locals = []types.Local{{Name: "jaeger-component-definition.*"}}
+
+	fetched, err := FetchDocuments(config)
+	require.NoError(t, err)
+	documents, err := parseDocuments(fetched)
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+	for _, document := range documents {
+		component := document.ComponentDefinition.Components[0]
+		require.Equal(t, "Jaeger", component.Title)
+		require.Equal(t, "si-4.4", component.ControlImplementations[0].ImplementedRequirements[0].ControlId)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		expected oscal.Format
+	}{
+		{name: "aggregate.yaml", expected: oscal.FormatYAML},
+		{name: "aggregate.json", expected: oscal.FormatJSON},
+		{name: "aggregate.XML", expected: oscal.FormatXML},
+		{name: "aggregate", expected: oscal.FormatYAML},
+		{name: "aggregate.yaml", format: "json", expected: oscal.FormatJSON},
+	}
+	for _, tt := range tests {
+		format, err := OutputFormat(types.ComponentsConfig{Name: tt.name, Format: tt.format})
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, format, tt.name)
+	}
+
+	_, err := OutputFormat(types.ComponentsConfig{Name: "aggregate.yaml", Format: "toml"})
+	require.ErrorContains(t, err, `unsupported format "toml"`)
+
+	config := types.ComponentsConfig{Name: "aggregate.xml", BaseDirectory: "../../../testdata/input/"}
+	config.Components.Locals = []types.Local{{Name: "jaeger-component-definition.yaml"}}
+	output, document, err := BuildOscalDocument(config)
+	require.NoError(t, err)
+	parsed, err := oscal.ParseComponentDocumentAs(oscal.FormatXML, []byte(output))
+	require.NoError(t, err)
+	require.Equal(t, document.ComponentDefinition.UUID, parsed.ComponentDefinition.UUID)
+	require.Equal(t, "si-4.4", parsed.ComponentDefinition.Components[0].ControlImplementations[0].ImplementedRequirements[0].ControlId)
+}
+
+func TestValidateDocuments(t *testing.T) {
+	t.Parallel()
+
+	config := types.ComponentsConfig{Name: "aggregate.json", BaseDirectory: "../../../testdata/input/"}
+	config.Metadata = types.Metadata{Title: "Aggregate", Version: "1.0.0", OscalVersion: "1.0.4"}
+	config.Components.Locals = []types.Local{{Name: "jaeger-component-definition.*"}}
+
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+
+	// The YAML fixture's party link is not a valid URI reference
+	err = ValidateDocuments(documents)
+	require.ErrorContains(t, err, "jaeger-component-definition.yaml: 1 OSCAL 1.0 schema violation(s)")
+	require.ErrorContains(t, err, "$.component-definition.metadata.parties[0].links[0].href")
+	require.NotContains(t, err.Error(), "jaeger-component-definition.json")
+
+	output, _, err := AggregateDocuments(config, documents[:1])
+	require.NoError(t, err)
+	require.NoError(t, ValidateOutput(config, output))
+}
+
+func TestCheckFile(t *testing.T) {
+	t.Parallel()
+
+	name := "../../../testdata/input/jaeger-component-definition.yaml"
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	require.Equal(t, []Finding{
+		{File: name, Path: "$.component-definition.metadata.parties[0].links[0].href", Message: "'<https://p1.dso.mil>' is not valid 'uri-reference'"},
+		{File: name, Path: "$.component-definition.components[0].responsible-roles[0].role-id", Message: `role "provider" is not defined in metadata.roles`},
+	}, CheckFile(name, data))
+
+	// A malformed uuid is reported once, by the schema
+	findings := CheckFile("bad.json", []byte(`{"component-definition": {"uuid": "bad", "metadata": {"title": "Bad", "last-modified": "2021-10-19T12:00:00Z", "version": "1", "oscal-version": "1.1.2"}}}`))
+	require.Len(t, findings, 1)
+	require.Equal(t, "$.component-definition.uuid", findings[0].Path)
+	require.Contains(t, findings[0].String(), "bad.json: $.component-definition.uuid: does not match pattern")
+
+	findings = CheckFile("broken.json", []byte(`{"component-definition": `))
+	require.Len(t, findings, 1)
+	require.Equal(t, "$", findings[0].Path)
+}
+
+func TestDeterministicUUID(t *testing.T) {
+	t.Parallel()
+
+	config := types.ComponentsConfig{Name: "aggregate.yaml", BaseDirectory: "../../../testdata/input/", Deterministic: true}
+	config.Metadata = types.Metadata{Title: "Aggregate", Version: "1.0.0", OscalVersion: "1.0.4"}
+	config.Components.Locals = []types.Local{{Name: "jaeger-component-definition.yaml"}}
+
+	first, document, err := BuildOscalDocument(config)
+	require.NoError(t, err)
+	second, _, err := BuildOscalDocument(config)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	id, err := uuid.Parse(document.ComponentDefinition.UUID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(5), id.Version())
+	require.Equal(t, "2021-10-19T12:00:00Z", document.ComponentDefinition.Metadata.LastModified)
+
+	// The uuid changes with the version, the content and the namespace, but not the last-modified
+	changed := func(modify func(*types.ComponentsConfig, *types.OscalComponentDocument)) string {
+		config, document := config, document
+		modify(&config, &document)
+		changed, err := DeterministicUUID(config, document)
+		require.NoError(t, err)
+		return changed
+	}
+	require.Equal(t, document.ComponentDefinition.UUID, changed(func(_ *types.ComponentsConfig, d *types.OscalComponentDocument) {
+		d.ComponentDefinition.Metadata.LastModified = "2024-01-01T00:00:00Z"
+	}))
+	require.NotEqual(t, document.ComponentDefinition.UUID, changed(func(c *types.ComponentsConfig, _ *types.OscalComponentDocument) {
+		c.Metadata.Version = "1.0.1"
+	}))
+	require.NotEqual(t, document.ComponentDefinition.UUID, changed(func(_ *types.ComponentsConfig, d *types.OscalComponentDocument) {
+		d.ComponentDefinition.Components = nil
+	}))
+	require.NotEqual(t, document.ComponentDefinition.UUID, changed(func(c *types.ComponentsConfig, _ *types.OscalComponentDocument) {
+		c.UUIDNamespace = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+	}))
+
+	config.UUIDNamespace = "not-a-uuid"
+	_, _, err = BuildOscalDocument(config)
+	require.ErrorContains(t, err, `invalid uuid-namespace "not-a-uuid"`)
+}
+
+func TestCanonicalOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	jaeger, err := os.ReadFile("../../../testdata/input/jaeger-component-definition.yaml")
+	require.NoError(t, err)
+	grafana := strings.NewReplacer("title: Jaeger\n", "title: Grafana\n", "50EE9EB1-0DA4-411C-8771-AA1725B27E22", "0B7A9F63-4C0E-4B39-9A51-0E8D2C3F4A1B",
+		"4D1938F1-E044-44AB-8CE7-E6131586CCB1", "6E2D4A1C-9B8F-4C7E-A5D3-2F1E0B9C8A7D").Replace(string(jaeger))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "jaeger.yaml"), jaeger, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "grafana.yaml"), []byte(grafana), 0644))
+
+	build := func(order ...string) (string, types.OscalComponentDocument) {
+		config := types.ComponentsConfig{Name: "aggregate.yaml", BaseDirectory: dir + "/", Deterministic: true, Canonical: true}
+		config.Metadata = types.Metadata{Title: "Aggregate", Version: "1.0.0", OscalVersion: "1.0.4"}
+		for _, name := range order {
+			config.Components.Locals = append(config.Components.Locals, types.Local{Name: name})
+		}
+		output, document, err := BuildOscalDocument(config)
+		require.NoError(t, err)
+		return output, document
+	}
+
+	first, document := build("jaeger.yaml", "grafana.yaml")
+	second, _ := build("grafana.yaml", "jaeger.yaml")
+	require.Equal(t, first, second)
+	require.Equal(t, "Grafana", document.ComponentDefinition.Components[0].Title)
+}
+
+func TestDuplicates(t *testing.T) {
+	t.Parallel()
+
+	component := func(uuid, title string, requirements ...types.ImplementedRequirement) types.DefinedComponent {
+		return types.DefinedComponent{UUID: uuid, Title: title, Type: "software", Description: title + " from " + uuid,
+			ControlImplementations: []types.ControlImplementation{{UUID: uuid, Source: "rev5", ImplementedRequirements: requirements}}}
+	}
+	document := func(components ...types.DefinedComponent) types.OscalComponentDocument {
+		return types.OscalComponentDocument{ComponentDefinition: types.ComponentDefinition{Components: components}}
+	}
+	fetched := []source.Document{{Name: "one.yaml"}, {Name: "two.yaml"}, {Name: "three.yaml"}}
+	documents := []types.OscalComponentDocument{
+		document(component("A", "Jaeger", types.ImplementedRequirement{UUID: "1", ControlId: "ac-2", Description: "first"}),
+			component("B", "Grafana")),
+		document(component("a", "Jaeger Tracing", types.ImplementedRequirement{UUID: "2", ControlId: "ac-2", Description: "second"},
+			types.ImplementedRequirement{UUID: "3", ControlId: "au-2"})),
+		document(component("C", "grafana")),
+	}
+	groups := groupComponents(fetched, documents)
+
+	_, _, collisions := deduplicate(DuplicateError, groups)
+	err := collisionError(collisions)
+	require.ErrorContains(t, err, "2 duplicate definition(s)")
+	require.ErrorContains(t, err, `component "Jaeger" (A) is defined by one.yaml, two.yaml`)
+	require.ErrorContains(t, err, `component "Grafana" (B) is defined by one.yaml, three.yaml`)
+
+	tests := []struct {
+		policy       DuplicatePolicy
+		uuids        []string
+		descriptions []string
+	}{
+		{policy: DuplicateKeepFirst, uuids: []string{"A", "B"}, descriptions: []string{"Jaeger from A", "Grafana from B"}},
+		{policy: DuplicateKeepLast, uuids: []string{"a", "C"}, descriptions: []string{"Jaeger Tracing from a", "grafana from C"}},
+		{policy: DuplicateDeepMerge, uuids: []string{"A", "B"}, descriptions: []string{"Jaeger Tracing from a", "grafana from C"}},
+	}
+	for _, tt := range tests {
+		components, uuids, collisions := deduplicate(tt.policy, groups)
+		require.Empty(t, collisions, tt.policy)
+		require.Equal(t, tt.uuids[0], uuids["a"], tt.policy)
+		require.Len(t, components, 2)
+		for i, component := range components {
+			require.Equal(t, tt.uuids[i], component.UUID, tt.policy)
+			require.Equal(t, tt.descriptions[i], component.Description, tt.policy)
+		}
+	}
+
+	// Requirements are merged by control id, keeping the uuid of the first
+	merged, _, _ := deduplicate(DuplicateDeepMerge, groups)
+	requirements := merged[0].ControlImplementations[0].ImplementedRequirements
+	require.Equal(t, []types.ImplementedRequirement{
+		{UUID: "1", ControlId: "ac-2", Description: "second"},
+		{UUID: "3", ControlId: "au-2"},
+	}, requirements)
+	require.Equal(t, "first", documents[0].ComponentDefinition.Components[0].ControlImplementations[0].ImplementedRequirements[0].Description)
+
+	// A definition sharing the uuid of one group and the title of another joins them
+	groups = groupComponents(fetched, []types.OscalComponentDocument{
+		document(component("A", "Jaeger")),
+		document(component("B", "Grafana")),
+		document(component("A", "Grafana")),
+	})
+	require.Len(t, groups, 1)
+	collision, ok := groups[0].collision()
+	require.True(t, ok)
+	require.Equal(t, Collision{Kind: "component", UUID: "A", Title: "Jaeger", Sources: []string{"one.yaml", "two.yaml", "three.yaml"}}, collision)
+	components, _, _ := deduplicate(DuplicateKeepLast, groups)
+	require.Len(t, components, 1)
+	require.Equal(t, "Grafana from A", components[0].Description)
+
+	_, err = ParseDuplicatePolicy("newest")
+	require.ErrorContains(t, err, `unsupported duplicates policy "newest"`)
+}
+
+func TestMergeBackMatter(t *testing.T) {
+	t.Parallel()
+
+	const shared, clashing = "4D1938F1-E044-44AB-8CE7-E6131586CCB1", "0F6B7D8E-2C3A-4B5D-9E1F-7A8B9C0D1E2F"
+	document := func(component string, resources ...types.Resources) types.OscalComponentDocument {
+		return types.OscalComponentDocument{ComponentDefinition: types.ComponentDefinition{
+			Components: []types.DefinedComponent{{UUID: component, Links: []types.Link{{Href: "#" + strings.ToLower(clashing)}, {Href: "#" + shared}}}},
+			BackMatter: types.BackMatter{Resources: resources},
+		}}
+	}
+	documents := []types.OscalComponentDocument{
+		document("A", types.Resources{UUID: shared, Title: "NIST SP 800-53"}, types.Resources{UUID: clashing, Title: "Lula"}),
+		document("B", types.Resources{UUID: strings.ToLower(shared), Title: "NIST SP 800-53"}, types.Resources{UUID: clashing, Title: "Kyverno"}),
+		document("C", types.Resources{UUID: clashing, Title: "Kyverno"}),
+	}
+
+	resources := mergeBackMatter(documents)
+	require.Len(t, resources, 3)
+	require.Equal(t, types.Resources{UUID: shared, Title: "NIST SP 800-53"}, resources[0])
+	require.Equal(t, types.Resources{UUID: clashing, Title: "Lula"}, resources[1])
+	remapped := resources[2].UUID
+	require.Equal(t, "Kyverno", resources[2].Title)
+	require.NotEqual(t, clashing, remapped)
+
+	// The first source keeps its links, later sources follow the resource they defined
+	require.Equal(t, "#"+strings.ToLower(clashing), documents[0].ComponentDefinition.Components[0].Links[0].Href)
+	require.Equal(t, "#"+remapped, documents[1].ComponentDefinition.Components[0].Links[0].Href)
+	require.Equal(t, "#"+remapped, documents[2].ComponentDefinition.Components[0].Links[0].Href)
+	require.Equal(t, "#"+shared, documents[1].ComponentDefinition.Components[0].Links[1].Href)
+
+	// The remapped uuid is stable across runs
+	again := mergeBackMatter([]types.OscalComponentDocument{
+		document("A", types.Resources{UUID: clashing, Title: "Lula"}),
+		document("B", types.Resources{UUID: clashing, Title: "Kyverno"}),
+	})
+	require.Equal(t, remapped, again[1].UUID)
+}
+
+func TestCapabilitiesAndImports(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"platform.yaml": definitionYAML("Platform", importsYAML("shared/istio.yaml")+`  components:
+  - uuid: 50EE9EB1-0DA4-411C-8771-AA1725B27E22
+    type: software
+    title: Jaeger
+    description: Tracing
+  capabilities:
+  - uuid: 3F4A0C2E-6C1B-4B8E-9A2D-5E6F7A8B9C0D
+    name: Observability
+    description: Tracing and metrics
+    incorporates-components:
+    - component-uuid: 50ee9eb1-0da4-411c-8771-aa1725b27e22
+      description: Traces requests
+`),
+		"jaeger.yaml": definitionYAML("Jaeger", `  components:
+  - uuid: 7A6B5C4D-3E2F-4A1B-9C8D-7E6F5A4B3C2D
+    type: software
+    title: Jaeger
+    description: Tracing, revised
+`),
+		"shared/istio.yaml": definitionYAML("Istio", `  components:
+  - uuid: 9E8D7C6B-5A4F-4E3D-8C2B-1A0F9E8D7C6B
+    type: software
+    title: Istio
+    description: Service mesh
+`),
+	})
+
+	config := types.ComponentsConfig{Name: filepath.Join(dir, "out", "aggregate.yaml"), BaseDirectory: dir + "/", Duplicates: "keep-last"}
+	config.Metadata = types.Metadata{Title: "Aggregate", Version: "1.0.0", OscalVersion: "1.0.4"}
+	config.Components.Locals = []types.Local{{Name: "platform.yaml"}, {Name: "jaeger.yaml"}}
+
+	// Imports are preserved by default, relative to the aggregated document, and capabilities follow the component kept
+	// in place of the one they incorporate
+	_, document, err := BuildOscalDocument(config)
+	require.NoError(t, err)
+	aggregate := document.ComponentDefinition
+	require.Equal(t, []types.ImportComponentDefinition{{Href: "../shared/istio.yaml"}}, aggregate.ImportComponentDefinitions)
+	require.Len(t, aggregate.Components, 1)
+	require.Equal(t, "Tracing, revised", aggregate.Components[0].Description)
+	require.Len(t, aggregate.Capabilities, 1)
+	require.Equal(t, "7A6B5C4D-3E2F-4A1B-9C8D-7E6F5A4B3C2D", aggregate.Capabilities[0].IncorporatesComponents[0].ComponentUuid)
+
+	// Resolving imports aggregates the imported components in their place
+	config.Imports = "resolve"
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"platform.yaml", filepath.Join("shared", "istio.yaml"), "jaeger.yaml"},
+		[]string{documents[0].Name, documents[1].Name, documents[2].Name})
+	_, document, err = AggregateDocuments(config, documents)
+	require.NoError(t, err)
+	require.Empty(t, document.ComponentDefinition.ImportComponentDefinitions)
+	require.Equal(t, "Istio", document.ComponentDefinition.Components[1].Title)
+
+	config.Imports = "inline"
+	_, err = FetchDocuments(config)
+	require.ErrorContains(t, err, `unsupported imports policy "inline"`)
+}
+
+func TestRebaseImport(t *testing.T) {
+	t.Parallel()
+
+	config := types.ComponentsConfig{Name: "/srv/oscal/out/aggregate.yaml", BaseDirectory: "/srv/oscal/"}
+	fileDoc := source.Document{Source: source.Source{Scheme: source.FileScheme, Location: "platform/platform.yaml"}, Name: "platform/platform.yaml"}
+	urlDoc := source.Document{Source: source.Source{Scheme: "https", Location: "https://example.com/oscal/component.yaml"}, Name: "https://example.com/oscal/component.yaml"}
+	gitDoc := source.Document{Source: source.Source{Scheme: source.GitScheme, Location: "https://github.com/org/repo", Path: "component.yaml", Ref: "v1.0.0"},
+		Name: "https://github.com/org/repo//component.yaml@v1.0.0"}
+
+	tests := []struct {
+		doc      source.Document
+		href     string
+		expected string
+		err      string
+	}{
+		{doc: fileDoc, href: "shared/istio.yaml", expected: "../platform/shared/istio.yaml"},
+		{doc: fileDoc, href: "/srv/shared/istio.yaml", expected: "/srv/shared/istio.yaml"},
+		{doc: fileDoc, href: "#0B931397-1A14-4785-8342-B5916AAF0751", expected: "#0B931397-1A14-4785-8342-B5916AAF0751"},
+		{doc: urlDoc, href: "../shared/istio.yaml", expected: "https://example.com/shared/istio.yaml"},
+		{doc: gitDoc, href: "https://example.com/istio.yaml", expected: "https://example.com/istio.yaml"},
+		{doc: gitDoc, href: "istio.yaml", err: `relative import "istio.yaml" of https://github.com/org/repo//component.yaml@v1.0.0 does not resolve from the aggregated document - set imports to resolve`},
+	}
+	for _, tt := range tests {
+		href, err := rebaseImport(config, tt.doc, tt.href)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err, tt.href)
+		require.Equal(t, tt.expected, href, tt.href)
+	}
+}
+
+func TestImportSource(t *testing.T) {
+	t.Parallel()
+
+	gitDoc := source.Document{
+		Source:   source.Source{Scheme: source.GitScheme, Location: "https://github.com/org/repo", Path: "oscal/component.yaml", Ref: "v1.0.0"},
+		Name:     "https://github.com/org/repo//oscal/component.yaml@v1.0.0",
+		Resolved: "0123456789abcdef0123456789abcdef01234567",
+	}
+	urlDoc := source.Document{Source: source.Source{Scheme: "https", Location: "https://example.com/oscal/component.yaml"}, Name: "https://example.com/oscal/component.yaml"}
+	ociDoc := source.Document{Source: source.Source{Scheme: source.OCIScheme, Location: "ghcr.io/org/component:1.0.0"}, Name: "ghcr.io/org/component:1.0.0"}
+	fileDoc := source.Document{Source: source.Source{Scheme: source.FileScheme, Location: "component.yaml"}, Name: "component.yaml"}
+
+	tests := []struct {
+		doc      source.Document
+		href     string
+		expected source.Source
+		err      string
+	}{
+		{doc: gitDoc, href: "../shared/istio.yaml", expected: source.Source{Scheme: source.GitScheme, Location: "https://github.com/org/repo", Path: "shared/istio.yaml", Ref: gitDoc.Resolved, Imported: true}},
+		{doc: gitDoc, href: "/istio.yaml", expected: source.Source{Scheme: source.GitScheme, Location: "https://github.com/org/repo", Path: "istio.yaml", Ref: gitDoc.Resolved, Imported: true}},
+		{doc: urlDoc, href: "istio.yaml", expected: source.Source{Scheme: "https", Location: "https://example.com/oscal/istio.yaml", Imported: true}},
+		{doc: ociDoc, href: "https://example.com/istio.yaml", expected: source.Source{Scheme: "https", Location: "https://example.com/istio.yaml", Imported: true}},
+		{doc: ociDoc, href: "istio.yaml", err: `relative import "istio.yaml" of ghcr.io/org/component:1.0.0 cannot be resolved for oci sources`},
+		{doc: fileDoc, href: "file:///srv/oscal/istio.yaml", expected: source.Source{Scheme: source.FileScheme, Location: filepath.FromSlash("/srv/oscal/istio.yaml"), Imported: true}},
+		{doc: urlDoc, href: "file:///etc/passwd", err: `import "file:///etc/passwd" of https://example.com/oscal/component.yaml refers to the local machine, which only local documents may import`},
+		{doc: gitDoc, href: "oci-layout:///srv/layout", err: `import "oci-layout:///srv/layout" of https://github.com/org/repo//oscal/component.yaml@v1.0.0 refers to the local machine`},
+		{doc: fileDoc, href: "oci://ghcr.io/org/istio:1.0.0", expected: source.Source{Scheme: source.OCIScheme, Location: "ghcr.io/org/istio:1.0.0", Imported: true}},
+		{doc: fileDoc, href: "oci://istio:1.0.0", err: `invalid import "oci://istio:1.0.0" of component.yaml`},
+		{doc: urlDoc, href: "git+https://github.com/org/istio.git//oscal.yaml@v1.0.0", expected: source.Source{Scheme: source.GitScheme, Location: "https://github.com/org/istio.git", Path: "oscal.yaml", Ref: "v1.0.0", Imported: true}},
+		{doc: gitDoc, href: "git://example.com/org/istio.git//oscal.yaml@v1.0.0", expected: source.Source{Scheme: source.GitScheme, Location: "git://example.com/org/istio.git", Path: "oscal.yaml", Ref: "v1.0.0", Imported: true}},
+		{doc: urlDoc, href: "git+file:///srv/git/istio.git//oscal.yaml@v1.0.0", err: "refers to the local machine"},
+		{doc: fileDoc, href: "zarf:packages/istio.tar.zst//oscal.yaml", expected: source.Source{Scheme: source.ZarfScheme, Location: filepath.FromSlash("packages/istio.tar.zst"), Path: "oscal.yaml", Imported: true}},
+		{doc: gitDoc, href: "zarf:///srv/packages/istio.tar.zst", err: "refers to the local machine"},
+		{doc: ociDoc, href: "helm:charts/istio@1.20.0", err: "refers to the local machine"},
+		{doc: fileDoc, href: "ftp://example.com/istio.yaml", err: `import "ftp://example.com/istio.yaml" of component.yaml: no fetcher is registered for scheme "ftp"`},
+	}
+	for _, tt := range tests {
+		src, err := importSource(tt.doc, types.OscalComponentDocument{}, tt.href)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err, tt.href)
+		require.Equal(t, tt.expected, src, tt.href)
+	}
+}
+
+// TestResolveSchemedImports checks that imports of packages, charts and git repositories use their own fetchers.
+func TestResolveSchemedImports(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"platform.yaml":                     definitionYAML("Platform", importsYAML("zarf:packages/istio.tar//oscal-component.yaml", "helm:charts/kiali@1.60.0-bb.2")),
+		"charts/kiali/Chart.yaml":           "apiVersion: v2\nname: kiali\nversion: 1.60.0-bb.2\n",
+		"charts/kiali/oscal-component.yaml": definitionYAML("Kiali", ""),
+	})
+
+	var pkg bytes.Buffer
+	tw := tar.NewWriter(&pkg)
+	for name, content := range map[string]string{"zarf.yaml": "kind: ZarfPackageConfig\n", "istio/oscal-component.yaml": definitionYAML("Istio", "")} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "packages"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "packages", "istio.tar"), pkg.Bytes(), 0644))
+
+	config := types.ComponentsConfig{BaseDirectory: dir, Imports: "resolve"}
+	config.Components.Locals = []types.Local{{Name: "platform.yaml"}}
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	names := []string{}
+	for _, doc := range documents {
+		names = append(names, filepath.ToSlash(doc.Name))
+	}
+	require.Equal(t, []string{"platform.yaml", "packages/istio.tar:istio/oscal-component.yaml", "charts/kiali@1.60.0-bb.2"}, names)
+	// A git import is fetched from its repository, as are its own relative imports
+	repo := gitRepository(t, map[string]string{
+		"oscal/istio.yaml": definitionYAML("Istio", importsYAML("kiali.yaml")),
+		"oscal/kiali.yaml": definitionYAML("Kiali", ""),
+	}, "v1.0.0")
+	dir = writeFiles(t, map[string]string{
+		"platform.yaml": definitionYAML("Platform", importsYAML("git+"+repo+"//oscal/istio.yaml@v1.0.0")),
+	})
+	config.BaseDirectory = dir
+	documents, err = FetchDocuments(config)
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+	require.Equal(t, source.Source{Scheme: source.GitScheme, Location: repo, Path: "oscal/istio.yaml", Ref: "v1.0.0", Imported: true}, documents[1].Source)
+	require.Equal(t, "oscal/kiali.yaml", documents[2].Source.Path)
+	require.Equal(t, documents[1].Resolved, documents[2].Source.Ref)
+	require.Contains(t, string(documents[2].Content), "title: Kiali")
+}
+
+func TestResolveImportsRecursively(t *testing.T) {
+	t.Parallel()
+
+	setup := func(files map[string]string) types.ComponentsConfig {
+		config := types.ComponentsConfig{BaseDirectory: writeFiles(t, files) + "/", Imports: "resolve"}
+		config.Components.Locals = []types.Local{{Name: "platform.yaml"}}
+		return config
+	}
+	names := func(documents []source.Document) []string {
+		var names []string
+		for _, doc := range documents {
+			names = append(names, filepath.ToSlash(doc.Name))
+		}
+		return names
+	}
+
+	// Imports are followed depth first, each definition is included once however often it is imported, and a
+	// back-matter reference imports the resource's rlink, verified against its hash
+	mesh := definitionYAML("Mesh", importsYAML("istio/istio.yaml", "#0B931397-1A14-4785-8342-B5916AAF0751")+`  back-matter:
+    resources:
+    - uuid: 0B931397-1A14-4785-8342-B5916AAF0751
+      rlinks:
+      - href: kiali.yaml
+        hashes:
+        - algorithm: SHA-256
+          value: `+fmt.Sprintf("%x", sha256.Sum256([]byte(definitionYAML("Kiali", ""))))+"\n")
+	config := setup(map[string]string{
+		"platform.yaml":         definitionYAML("Platform", importsYAML("mesh/mesh.yaml", "mesh/istio/istio.yaml")),
+		"mesh/mesh.yaml":        mesh,
+		"mesh/istio/istio.yaml": definitionYAML("Istio", ""),
+		"mesh/kiali.yaml":       definitionYAML("Kiali", ""),
+	})
+	documents, err := FetchDocuments(config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"platform.yaml", "mesh/mesh.yaml", "mesh/istio/istio.yaml", "mesh/kiali.yaml"}, names(documents))
+
+	config = setup(map[string]string{
+		"platform.yaml":         definitionYAML("Platform", importsYAML("mesh/mesh.yaml")),
+		"mesh/mesh.yaml":        mesh,
+		"mesh/istio/istio.yaml": definitionYAML("Istio", ""),
+		"mesh/kiali.yaml":       definitionYAML("Kiali, changed", ""),
+	})
+	_, err = FetchDocuments(config)
+	require.ErrorContains(t, err, "failed to fetch #0B931397-1A14-4785-8342-B5916AAF0751 imported by mesh/mesh.yaml")
+	require.ErrorContains(t, err, "digest mismatch")
+
+	config = setup(map[string]string{
+		"platform.yaml": definitionYAML("Platform", importsYAML("a.yaml")),
+		"a.yaml":        definitionYAML("A", importsYAML("b.yaml")),
+		"b.yaml":        definitionYAML("B", importsYAML("a.yaml")),
+	})
+	_, err = FetchDocuments(config)
+	require.EqualError(t, err, "import cycle: platform.yaml -> a.yaml -> b.yaml -> a.yaml")
+
+	// Documents declared in the config are followed too, so that a cycle between them is found
+	config = setup(map[string]string{
+		"a.yaml": definitionYAML("A", importsYAML("b.yaml")),
+		"b.yaml": definitionYAML("B", importsYAML("a.yaml")),
+	})
+	config.Components.Locals = []types.Local{{Name: "a.yaml"}, {Name: "b.yaml"}}
+	_, err = FetchDocuments(config)
+	require.EqualError(t, err, "import cycle: a.yaml -> b.yaml -> a.yaml")
+
+	// A declared document imported by another keeps its place, with its own imports following the importer
+	config = setup(map[string]string{
+		"a.yaml": definitionYAML("A", importsYAML("b.yaml")),
+		"b.yaml": definitionYAML("B", importsYAML("c.yaml")),
+		"c.yaml": definitionYAML("C", ""),
+	})
+	config.Components.Locals = []types.Local{{Name: "a.yaml"}, {Name: "b.yaml"}}
+	documents, err = FetchDocuments(config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.yaml", "c.yaml", "b.yaml"}, names(documents))
+
+	config = setup(map[string]string{
+		"platform.yaml": definitionYAML("Platform", importsYAML("a.yaml")),
+		"a.yaml":        definitionYAML("A", importsYAML("b.yaml")),
+		"b.yaml":        definitionYAML("B", importsYAML("c.yaml")),
+		"c.yaml":        definitionYAML("C", ""),
+	})
+	config.ImportDepth = 2
+	_, err = FetchDocuments(config)
+	require.EqualError(t, err, "import c.yaml of b.yaml exceeds the maximum import depth of 2: platform.yaml -> a.yaml -> b.yaml")
+	config.ImportDepth = 3
+	documents, err = FetchDocuments(config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"platform.yaml", "a.yaml", "b.yaml", "c.yaml"}, names(documents))
+
+	config = setup(map[string]string{"platform.yaml": definitionYAML("Platform", importsYAML("#6E2D4A1C-9B8F-4C7E-A5D3-2F1E0B9C8A7D"))})
+	_, err = FetchDocuments(config)
+	require.EqualError(t, err, "import #6E2D4A1C-9B8F-4C7E-A5D3-2F1E0B9C8A7D of platform.yaml: no back-matter resource has uuid 6E2D4A1C-9B8F-4C7E-A5D3-2F1E0B9C8A7D")
+}
@@ -0,0 +1,331 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+)
+
+// DuplicatePolicy decides how components that more than one source defines are aggregated.
+type DuplicatePolicy string
+
+const (
+	// DuplicateError fails the aggregation, listing every collision
+	DuplicateError DuplicatePolicy = "error"
+	// DuplicateKeepFirst keeps the component from the source declared first
+	DuplicateKeepFirst DuplicatePolicy = "keep-first"
+	// DuplicateKeepLast keeps the component from the source declared last
+	DuplicateKeepLast DuplicatePolicy = "keep-last"
+	// DuplicateDeepMerge merges the components in the order their sources are declared
+	DuplicateDeepMerge DuplicatePolicy = "deep-merge"
+)
+
+// ParseDuplicatePolicy returns the policy of a config's duplicates field, defaulting to DuplicateError.
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(name); policy {
+	case "":
+		return DuplicateError, nil
+	case DuplicateError, DuplicateKeepFirst, DuplicateKeepLast, DuplicateDeepMerge:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unsupported duplicates policy %q - must be one of error, keep-first, keep-last or deep-merge", name)
+}
+
+// Collision is a component or capability defined more than once, by uuid or case-insensitive title or name.
+type Collision struct {
+	// Kind is component or capability
+	Kind string
+	// UUID and Title are those of the first definition
+	UUID  string
+	Title string
+	// Sources names the document of each definition, in the order the sources are declared
+	Sources []string
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("%s %q (%s) is defined by %s", c.Kind, c.Title, c.UUID, strings.Join(c.Sources, ", "))
+}
+
+// Collisions returns every component and capability that previously fetched documents define more than once.
+func Collisions(fetched []source.Document) ([]Collision, error) {
+	documents, err := parseDocuments(fetched)
+	if err != nil {
+		return nil, err
+	}
+	var collisions []Collision
+	for _, group := range groupComponents(fetched, documents) {
+		if collision, ok := group.collision(); ok {
+			collisions = append(collisions, collision)
+		}
+	}
+	for _, group := range groupCapabilities(fetched, documents) {
+		if collision, ok := group.collision(); ok {
+			collisions = append(collisions, collision)
+		}
+	}
+	return collisions, nil
+}
+
+// identity returns the uuid and title, or name, that identify a component or capability.
+type identity[T any] func(T) (string, string)
+
+func componentIdentity(c types.DefinedComponent) (string, string) { return c.UUID, c.Title }
+func capabilityIdentity(c types.Capability) (string, string)      { return c.UUID, c.Name }
+
+// sourced is a component or capability along with the name of the document that defined it.
+type sourced[T any] struct {
+	source string
+	item   T
+	// order is the position of the definition across every document
+	order int
+}
+
+// definitions holds every definition of one component or capability.
+type definitions[T any] struct {
+	kind     string
+	identity identity[T]
+	items    []sourced[T]
+}
+
+func (d definitions[T]) collision() (Collision, bool) {
+	if len(d.items) < 2 {
+		return Collision{}, false
+	}
+	uuid, title := d.identity(d.items[0].item)
+	collision := Collision{Kind: d.kind, UUID: uuid, Title: title}
+	for _, definition := range d.items {
+		collision.Sources = append(collision.Sources, definition.source)
+	}
+	return collision, true
+}
+
+func groupComponents(fetched []source.Document, documents []types.OscalComponentDocument) []definitions[types.DefinedComponent] {
+	return group(fetched, documents, "component", componentIdentity, func(d types.ComponentDefinition) []types.DefinedComponent {
+		return d.Components
+	})
+}
+
+func groupCapabilities(fetched []source.Document, documents []types.OscalComponentDocument) []definitions[types.Capability] {
+	return group(fetched, documents, "capability", capabilityIdentity, func(d types.ComponentDefinition) []types.Capability {
+		return d.Capabilities
+	})
+}
+
+// group groups definitions by identity in order of first definition, joining the groups a definition matches by
+// uuid and by title.
+func group[T any](fetched []source.Document, documents []types.OscalComponentDocument, kind string, id identity[T],
+	items func(types.ComponentDefinition) []T) []definitions[T] {
+	var (
+		groups  []definitions[T]
+		byUUID  = map[string]int{}
+		byTitle = map[string]int{}
+		// joined gives the group each group was joined into, itself when it was not
+		joined = []int{}
+		order  int
+	)
+	find := func(index int) int {
+		for joined[index] != index {
+			index = joined[index]
+		}
+		return index
+	}
+
+	for i, doc := range documents {
+		for _, item := range items(doc.ComponentDefinition) {
+			uuid, title := id(item)
+			uuidKey := strings.ToLower(uuid)
+			titleKey := strings.ToLower(strings.TrimSpace(title))
+
+			uuidIndex, uuidFound := byUUID[uuidKey]
+			titleIndex, titleFound := byTitle[titleKey]
+			var index int
+			switch {
+			case uuidFound && titleFound:
+				index = find(uuidIndex)
+				if other := find(titleIndex); other != index {
+					// The later group joins the earlier one, so that groups stay in the order they were first defined
+					if other < index {
+						index, other = other, index
+					}
+					items := append(groups[index].items, groups[other].items...)
+					sort.SliceStable(items, func(a, b int) bool { return items[a].order < items[b].order })
+					groups[index].items = items
+					groups[other].items = nil
+					joined[other] = index
+				}
+			case uuidFound:
+				index = find(uuidIndex)
+			case titleFound:
+				index = find(titleIndex)
+			default:
+				index = len(groups)
+				groups = append(groups, definitions[T]{kind: kind, identity: id})
+				joined = append(joined, index)
+			}
+
+			groups[index].items = append(groups[index].items, sourced[T]{source: fetched[i].Name, item: item, order: order})
+			order++
+			if !uuidFound {
+				byUUID[uuidKey] = index
+			}
+			if !titleFound {
+				byTitle[titleKey] = index
+			}
+		}
+	}
+
+	grouped := []definitions[T]{}
+	for _, group := range groups {
+		if len(group.items) > 0 {
+			grouped = append(grouped, group)
+		}
+	}
+	return grouped
+}
+
+// deduplicate applies a duplicates policy to grouped definitions and returns the uuid each resolved to, keyed by
+// lower-cased uuid. Under DuplicateError the collisions are returned instead.
+func deduplicate[T any](policy DuplicatePolicy, groups []definitions[T]) ([]T, map[string]string, []Collision) {
+	var (
+		resolved   = []T{}
+		uuids      = map[string]string{}
+		collisions []Collision
+	)
+	for _, group := range groups {
+		if collision, ok := group.collision(); ok && policy == DuplicateError {
+			collisions = append(collisions, collision)
+			continue
+		}
+
+		var item T
+		switch policy {
+		case DuplicateKeepLast:
+			item = group.items[len(group.items)-1].item
+		case DuplicateDeepMerge:
+			item = group.items[0].item
+			for _, definition := range group.items[1:] {
+				mergeValues(reflect.ValueOf(&item).Elem(), reflect.ValueOf(definition.item))
+			}
+		default:
+			item = group.items[0].item
+		}
+		resolved = append(resolved, item)
+
+		resolvedUUID, _ := group.identity(item)
+		for _, definition := range group.items {
+			uuid, _ := group.identity(definition.item)
+			uuids[strings.ToLower(uuid)] = resolvedUUID
+		}
+	}
+	return resolved, uuids, collisions
+}
+
+// incorporateComponents points the incorporates-components of capabilities at the components they resolved to.
+func incorporateComponents(capabilities []types.Capability, uuids map[string]string) {
+	for i := range capabilities {
+		var (
+			incorporated []types.IncorporatesComponent
+			seen         = map[string]bool{}
+		)
+		for _, component := range capabilities[i].IncorporatesComponents {
+			if resolved, ok := uuids[strings.ToLower(component.ComponentUuid)]; ok {
+				component.ComponentUuid = resolved
+			}
+			if key := strings.ToLower(component.ComponentUuid); !seen[key] {
+				seen[key] = true
+				incorporated = append(incorporated, component)
+			}
+		}
+		capabilities[i].IncorporatesComponents = incorporated
+	}
+}
+
+// collisionError lists collisions found under DuplicateError.
+func collisionError(collisions []Collision) error {
+	lines := make([]string, 0, len(collisions))
+	for _, collision := range collisions {
+		lines = append(lines, "  "+collision.String())
+	}
+	return fmt.Errorf("%d duplicate definition(s) - set duplicates to keep-first, keep-last or deep-merge to aggregate them:\n%s",
+		len(collisions), strings.Join(lines, "\n"))
+}
+
+// mergeValues deep-merges src into dst, keeping the first uuid and merging slice items that share a mergeKey.
+func mergeValues(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).Name == "UUID" && !dst.Field(i).IsZero() {
+				continue
+			}
+			mergeValues(dst.Field(i), src.Field(i))
+		}
+	case reflect.Ptr:
+		switch {
+		case src.IsNil():
+		case dst.IsNil():
+			dst.Set(src)
+		default:
+			merged := reflect.New(dst.Type().Elem())
+			merged.Elem().Set(dst.Elem())
+			mergeValues(merged.Elem(), src.Elem())
+			dst.Set(merged)
+		}
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+		merged = reflect.AppendSlice(merged, dst)
+		for i := 0; i < src.Len(); i++ {
+			item := src.Index(i)
+			if index := indexOf(merged, item); index >= 0 {
+				mergeValues(merged.Index(index), item)
+				continue
+			}
+			merged = reflect.Append(merged, item)
+		}
+		dst.Set(merged)
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// indexOf returns the index of the item in a slice that is identical to, or shares the merge key of, an item.
+func indexOf(slice, item reflect.Value) int {
+	key := mergeKey(item.Interface())
+	for i := 0; i < slice.Len(); i++ {
+		existing := slice.Index(i).Interface()
+		if (key != "" && mergeKey(existing) == key) || reflect.DeepEqual(existing, item.Interface()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeKey identifies slice items describing the same thing, as sources may have generated different uuids for it.
+func mergeKey(item interface{}) string {
+	switch v := item.(type) {
+	case types.ControlImplementation:
+		return v.Source
+	case types.ImplementedRequirement:
+		return v.ControlId
+	case types.Statement:
+		return v.StatementId
+	case types.ResponsibleRole:
+		return v.RoleId
+	case types.SetParameter:
+		return v.ParamId
+	case types.Protocol:
+		return v.Name
+	case types.IncorporatesComponent:
+		return strings.ToLower(v.ComponentUuid)
+	}
+	return ""
+}
@@ -0,0 +1,327 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+)
+
+// ImportPolicy decides what happens to the import-component-definitions of the aggregated sources.
+type ImportPolicy string
+
+const (
+	// ImportPreserve carries every import over to the aggregated document
+	ImportPreserve ImportPolicy = "preserve"
+	// ImportResolve fetches every imported definition and aggregates it in place of the import
+	ImportResolve ImportPolicy = "resolve"
+)
+
+// ParseImportPolicy returns the policy of a config's imports field, defaulting to ImportPreserve.
+func ParseImportPolicy(name string) (ImportPolicy, error) {
+	switch policy := ImportPolicy(name); policy {
+	case "":
+		return ImportPreserve, nil
+	case ImportPreserve, ImportResolve:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unsupported imports policy %q - must be one of preserve or resolve", name)
+}
+
+// aggregateImports returns each import of the documents once, rebased, or none when imports are resolved.
+func aggregateImports(config types.ComponentsConfig, fetched []source.Document, documents []types.OscalComponentDocument) ([]types.ImportComponentDefinition, error) {
+	policy, err := ParseImportPolicy(config.Imports)
+	if err != nil || policy == ImportResolve {
+		return nil, err
+	}
+
+	var (
+		imports []types.ImportComponentDefinition
+		seen    = map[string]bool{}
+	)
+	for i, doc := range documents {
+		for _, imported := range doc.ComponentDefinition.ImportComponentDefinitions {
+			if imported.Href, err = rebaseImport(config, fetched[i], imported.Href); err != nil {
+				return nil, err
+			}
+			if !seen[imported.Href] {
+				seen[imported.Href] = true
+				imports = append(imports, imported)
+			}
+		}
+	}
+	return imports, nil
+}
+
+// rebaseImport rewrites a relative import href to refer to the same definition from the aggregated document.
+func rebaseImport(config types.ComponentsConfig, doc source.Document, href string) (string, error) {
+	if href == "" || strings.HasPrefix(href, "#") || source.Scheme(href) != "" {
+		return href, nil
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href, nil
+	}
+
+	switch doc.Source.Scheme {
+	case source.FileScheme:
+		location := filepath.FromSlash(href)
+		if filepath.IsAbs(location) {
+			return href, nil
+		}
+		imported, err := filepath.Abs(localDocumentPath(config.BaseDirectory, filepath.Join(filepath.Dir(doc.Name), location)))
+		if err != nil {
+			return "", err
+		}
+		output, err := filepath.Abs(filepath.Dir(config.Name))
+		if err != nil {
+			return "", err
+		}
+		rebased, err := filepath.Rel(output, imported)
+		if err != nil {
+			return "", err
+		}
+		return filepath.ToSlash(rebased), nil
+	case "https", "http":
+		base, err := url.Parse(doc.Source.Location)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	return "", fmt.Errorf("relative import %q of %s does not resolve from the aggregated document - set imports to resolve to aggregate the imported definitions instead", href, doc.Name)
+}
+
+// DefaultImportDepth is the number of levels of imports resolved when the config does not set import-depth.
+const DefaultImportDepth = 8
+
+// importResolver follows the import-component-definitions of documents recursively.
+type importResolver struct {
+	opts     source.Options
+	maxDepth int
+	// included holds the sha256 of every document already aggregated, so that each is included once
+	included map[string]bool
+	// explored holds the sha256 of every document whose imports have been followed, or are being followed
+	explored map[string]bool
+}
+
+// resolveImports returns each document followed by the definitions it imports, each once, failing on cycles and
+// on imports nested deeper than maxDepth.
+func resolveImports(fetched []source.Document, opts source.Options, maxDepth int) ([]source.Document, error) {
+	if maxDepth < 1 {
+		maxDepth = DefaultImportDepth
+	}
+	r := importResolver{opts: opts, maxDepth: maxDepth, included: map[string]bool{}, explored: map[string]bool{}}
+	for _, doc := range fetched {
+		r.included[contentKey(doc)] = true
+	}
+
+	documents := []source.Document{}
+	for _, doc := range fetched {
+		// A declared document another one imports has had its imports followed from there already
+		if r.explored[contentKey(doc)] {
+			documents = append(documents, doc)
+			continue
+		}
+		resolved, err := r.resolve(doc, nil)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, resolved...)
+	}
+	return documents, nil
+}
+
+// resolve returns a document followed by its imports, depth first, leaving declared documents where declared.
+func (r *importResolver) resolve(doc source.Document, chain []source.Document) ([]source.Document, error) {
+	chain = append(chain[:len(chain):len(chain)], doc)
+	r.explored[contentKey(doc)] = true
+
+	parsed, err := oscal.ParseComponentDocumentAs(oscal.DetectFormat(doc.Name, doc.Content), doc.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %w", doc.Name, err)
+	}
+
+	documents := []source.Document{doc}
+	for _, imported := range parsed.ComponentDefinition.ImportComponentDefinitions {
+		if len(chain) > r.maxDepth {
+			return nil, fmt.Errorf("import %s of %s exceeds the maximum import depth of %d: %s", imported.Href, doc.Name, r.maxDepth, describeChain(chain))
+		}
+		src, err := importSource(doc, parsed, imported.Href)
+		if err != nil {
+			return nil, err
+		}
+		importedDocs, err := source.Fetch(src, r.opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s imported by %s: %w", imported.Href, doc.Name, err)
+		}
+
+		for _, importedDoc := range importedDocs {
+			key := contentKey(importedDoc)
+			for _, importer := range chain {
+				if contentKey(importer) == key {
+					return nil, fmt.Errorf("import cycle: %s -> %s", describeChain(chain), importedDoc.Name)
+				}
+			}
+			if r.explored[key] {
+				continue
+			}
+
+			resolved, err := r.resolve(importedDoc, chain)
+			if err != nil {
+				return nil, err
+			}
+			if r.included[key] {
+				resolved = resolved[1:]
+			}
+			r.included[key] = true
+			documents = append(documents, resolved...)
+		}
+	}
+	return documents, nil
+}
+
+// contentKey identifies a document by its content, which is the same however the document was reached.
+func contentKey(doc source.Document) string {
+	sum := sha256.Sum256(doc.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+func describeChain(chain []source.Document) string {
+	names := make([]string, 0, len(chain))
+	for _, doc := range chain {
+		names = append(names, doc.Name)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// importSource returns the source an import href refers to - a back-matter rlink for #uuid, a source URI when it has
+// a scheme, otherwise a location relative to the document.
+func importSource(doc source.Document, parsed types.OscalComponentDocument, href string) (source.Source, error) {
+	if href == "" {
+		return source.Source{}, fmt.Errorf("an import of %s has no href", doc.Name)
+	}
+	if strings.HasPrefix(href, "#") {
+		rlink, err := backMatterLink(parsed, strings.TrimPrefix(href, "#"))
+		if err != nil {
+			return source.Source{}, fmt.Errorf("import %s of %s: %w", href, doc.Name, err)
+		}
+		if strings.HasPrefix(rlink.Href, "#") {
+			return source.Source{}, fmt.Errorf("import %s of %s: resource links to another resource, %s", href, doc.Name, rlink.Href)
+		}
+		src, err := importSource(doc, parsed, rlink.Href)
+		if err != nil {
+			return source.Source{}, err
+		}
+		src.Digest = rlinkDigest(rlink)
+		return src, nil
+	}
+
+	if source.Scheme(href) != "" {
+		src, err := source.Parse(href)
+		if err != nil {
+			return source.Source{}, fmt.Errorf("invalid import %q of %s: %w", href, doc.Name, err)
+		}
+		if _, err := source.Lookup(src.Scheme); err != nil {
+			return source.Source{}, fmt.Errorf("import %q of %s: %w", href, doc.Name, err)
+		}
+		if isLocal(src) {
+			if !isLocal(doc.Source) {
+				return source.Source{}, fmt.Errorf("import %q of %s refers to the local machine, which only local documents may import", href, doc.Name)
+			}
+			// Like a relative href, a relative path is beside the importing file
+			if doc.Source.Scheme == source.FileScheme && localSchemes[src.Scheme] && !filepath.IsAbs(src.Location) {
+				src.Location = filepath.Join(filepath.Dir(doc.Name), src.Location)
+			}
+		}
+		src.Imported = true
+		return src, nil
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return source.Source{}, fmt.Errorf("invalid import %q of %s: %w", href, doc.Name, err)
+	}
+
+	switch doc.Source.Scheme {
+	case source.FileScheme:
+		location := filepath.FromSlash(href)
+		if !filepath.IsAbs(location) {
+			location = filepath.Join(filepath.Dir(doc.Name), location)
+		}
+		return source.Source{Scheme: source.FileScheme, Location: location, Imported: true}, nil
+	case source.GitScheme:
+		commit := doc.Resolved
+		if commit == "" {
+			commit = doc.Source.Ref
+		}
+		// An absolute path is relative to the root of the repository
+		file := strings.TrimPrefix(href, "/")
+		if !path.IsAbs(href) {
+			file = path.Join(path.Dir(doc.Source.Path), href)
+		}
+		return source.Source{
+			Scheme:   source.GitScheme,
+			Location: doc.Source.Location,
+			Path:     file,
+			Ref:      commit,
+			Imported: true,
+		}, nil
+	case "https", "http":
+		base, err := url.Parse(doc.Source.Location)
+		if err != nil {
+			return source.Source{}, err
+		}
+		resolved := base.ResolveReference(ref)
+		return source.Source{Scheme: resolved.Scheme, Location: resolved.String(), Imported: true}, nil
+	}
+	return source.Source{}, fmt.Errorf("relative import %q of %s cannot be resolved for %s sources", href, doc.Name, doc.Source.Scheme)
+}
+
+// localSchemes are the schemes of sources on the local machine, which remote documents may not import.
+var localSchemes = map[string]bool{
+	source.FileScheme:      true,
+	source.OCILayoutScheme: true,
+	source.ZarfScheme:      true,
+	source.HelmScheme:      true,
+}
+
+// isLocal reports whether a source is on the local machine, including a git repository cloned from a local path.
+func isLocal(src source.Source) bool {
+	if src.Scheme == source.GitScheme {
+		return strings.HasPrefix(src.Location, "file:") || !strings.Contains(src.Location, ":")
+	}
+	return localSchemes[src.Scheme]
+}
+
+// backMatterLink returns the first rlink of the back-matter resource with a uuid.
+func backMatterLink(document types.OscalComponentDocument, uuid string) (types.Rlinks, error) {
+	for _, resource := range document.ComponentDefinition.BackMatter.Resources {
+		if !strings.EqualFold(resource.UUID, uuid) {
+			continue
+		}
+		if len(resource.Rlinks) == 0 {
+			return types.Rlinks{}, fmt.Errorf("back-matter resource %s has no rlinks", resource.UUID)
+		}
+		return resource.Rlinks[0], nil
+	}
+	return types.Rlinks{}, fmt.Errorf("no back-matter resource has uuid %s", uuid)
+}
+
+// rlinkDigest returns the <algorithm>:<hex> digest of the first SHA-256 or SHA-512 hash of an rlink.
+func rlinkDigest(rlink types.Rlinks) string {
+	for _, hash := range rlink.Hashes {
+		switch algorithm := strings.ToLower(strings.ReplaceAll(hash.Algorithm, "-", "")); algorithm {
+		case "sha256", "sha512":
+			return algorithm + ":" + strings.ToLower(strings.TrimSpace(hash.Value))
+		}
+	}
+	return ""
+}
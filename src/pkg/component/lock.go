@@ -0,0 +1,74 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+)
+
+// NewLockfile records the resolved identity and sha256 of every fetched document.
+func NewLockfile(documents []source.Document) types.Lockfile {
+	lock := types.Lockfile{Sources: []types.LockedSource{}}
+
+	for _, doc := range documents {
+		sum := sha256.Sum256(doc.Content)
+		locked := types.LockedSource{
+			Source:   doc.Source.String(),
+			Resolved: doc.Resolved,
+			Sha256:   hex.EncodeToString(sum[:]),
+		}
+		if doc.Name != locked.Source {
+			locked.Name = doc.Name
+		}
+		lock.Sources = append(lock.Sources, locked)
+	}
+	return lock
+}
+
+// VerifyLockfile returns an error describing every source that resolved differently from the lockfile.
+func VerifyLockfile(expected, actual types.Lockfile) error {
+	key := func(s types.LockedSource) string { return s.Source + "\x00" + s.Name }
+
+	remaining := map[string]types.LockedSource{}
+	for _, locked := range expected.Sources {
+		remaining[key(locked)] = locked
+	}
+
+	var problems []string
+	for _, current := range actual.Sources {
+		locked, ok := remaining[key(current)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not in the lockfile", describeLocked(current)))
+			continue
+		}
+		delete(remaining, key(current))
+
+		if locked.Resolved != current.Resolved {
+			problems = append(problems, fmt.Sprintf("%s resolved to %q, lockfile has %q", describeLocked(current), current.Resolved, locked.Resolved))
+		}
+		if locked.Sha256 != current.Sha256 {
+			problems = append(problems, fmt.Sprintf("%s has sha256 %s, lockfile has %s", describeLocked(current), current.Sha256, locked.Sha256))
+		}
+	}
+	for _, locked := range expected.Sources {
+		if _, ok := remaining[key(locked)]; ok {
+			problems = append(problems, fmt.Sprintf("%s is in the lockfile but no longer fetched", describeLocked(locked)))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("sources do not match the lockfile:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func describeLocked(s types.LockedSource) string {
+	if s.Name != "" {
+		return fmt.Sprintf("%s (%s)", s.Source, s.Name)
+	}
+	return s.Source
+}
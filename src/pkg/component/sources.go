@@ -0,0 +1,94 @@
+package component
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+)
+
+// configSources converts every entry of the components config into a Source, in the order the entries are declared.
+func configSources(components types.Component) ([]source.Source, error) {
+	sources := []source.Source{}
+
+	for _, local := range components.Locals {
+		sources = append(sources, source.Source{Scheme: source.FileScheme, Location: local.Name, Digest: local.Hash, Exclude: local.Exclude})
+	}
+
+	for _, remote := range components.Remotes {
+		if remote.Git == "" {
+			continue
+		}
+		src, err := source.ParseGitReference(remote.Git)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case src.Path == "":
+			src.Path = remote.Path
+		case remote.Path != "" && remote.Path != src.Path:
+			return nil, fmt.Errorf("remote %q includes the path %q, which conflicts with its path field %q", remote.Git, src.Path, remote.Path)
+		}
+		src.Digest = remote.Hash
+		sources = append(sources, src)
+	}
+
+	for _, u := range components.URLs {
+		uri, err := url.Parse(u.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse url source %q: %w", u.URL, err)
+		}
+		if uri.Scheme != "https" && uri.Scheme != "http" {
+			return nil, fmt.Errorf("url source %q must be an http or https URL", u.URL)
+		}
+		if u.Checksum == "" {
+			return nil, fmt.Errorf("url source %q must specify a checksum", u.URL)
+		}
+		sources = append(sources, source.Source{Scheme: uri.Scheme, Location: u.URL, Digest: u.Checksum})
+	}
+
+	for _, o := range components.OCI {
+		if o.Layout != "" {
+			sources = append(sources, source.Source{Scheme: source.OCILayoutScheme, Location: o.Layout, Ref: o.Ref, MediaType: o.MediaType})
+			continue
+		}
+		if o.Ref == "" {
+			return nil, fmt.Errorf("oci source must specify a ref or a layout")
+		}
+		sources = append(sources, source.Source{Scheme: source.OCIScheme, Location: o.Ref, MediaType: o.MediaType})
+	}
+
+	for _, z := range components.Zarf {
+		if z.Package == "" {
+			return nil, fmt.Errorf("zarf source must specify a package")
+		}
+		sources = append(sources, source.Source{Scheme: source.ZarfScheme, Location: z.Package, Path: z.Path})
+	}
+
+	for _, h := range components.Helm {
+		if h.Chart == "" {
+			return nil, fmt.Errorf("helm source must specify a chart")
+		}
+		if h.Repo == "" {
+			sources = append(sources, source.Source{Scheme: source.HelmScheme, Location: h.Chart, Path: h.Path, Ref: h.Version})
+			continue
+		}
+		if h.Version == "" {
+			return nil, fmt.Errorf("helm source %s from %s must specify a version", h.Chart, h.Repo)
+		}
+		location := strings.TrimSuffix(h.Repo, "/") + "/" + h.Chart
+		sources = append(sources, source.Source{Scheme: source.HelmRepoScheme, Location: location, Path: h.Path, Ref: h.Version})
+	}
+
+	for _, generic := range components.Sources {
+		src, err := source.Parse(generic.URI)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+
+	return sources, nil
+}
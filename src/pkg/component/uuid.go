@@ -0,0 +1,54 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/google/uuid"
+)
+
+// Namespace is the default UUIDv5 namespace of deterministic component-definition uuids.
+var Namespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/defenseunicorns/component-generator"))
+
+// DeterministicUUID derives a UUIDv5 from the config's name and version and the document's content, less its uuid and
+// last-modified.
+func DeterministicUUID(config types.ComponentsConfig, document types.OscalComponentDocument) (string, error) {
+	namespace := Namespace
+	if config.UUIDNamespace != "" {
+		parsed, err := uuid.Parse(config.UUIDNamespace)
+		if err != nil {
+			return "", fmt.Errorf("invalid uuid-namespace %q: %w", config.UUIDNamespace, err)
+		}
+		namespace = parsed
+	}
+
+	document.ComponentDefinition.UUID = ""
+	document.ComponentDefinition.Metadata.LastModified = ""
+	content, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(content)
+
+	name := config.Name + "\n" + config.Metadata.Version + "\n" + hex.EncodeToString(hash[:])
+	return uuid.NewSHA1(namespace, []byte(name)).String(), nil
+}
+
+// latestModified returns the latest last-modified of the documents, or the Unix epoch when none has one.
+func latestModified(documents []types.OscalComponentDocument) string {
+	var latest time.Time
+	for _, doc := range documents {
+		modified, err := time.Parse(time.RFC3339, doc.ComponentDefinition.Metadata.LastModified)
+		if err == nil && modified.After(latest) {
+			latest = modified
+		}
+	}
+	if latest.IsZero() {
+		latest = time.Unix(0, 0)
+	}
+	return latest.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,78 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+	"github.com/defenseunicorns/component-generator/src/internal/schema"
+	"github.com/defenseunicorns/component-generator/src/internal/semantic"
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+	"github.com/defenseunicorns/component-generator/src/pkg/source"
+)
+
+// ValidateDocuments validates every fetched document against the OSCAL JSON schema of its version.
+func ValidateDocuments(documents []source.Document) error {
+	var errs []error
+	for _, doc := range documents {
+		if err := schema.ValidateData(oscal.DetectFormat(doc.Name, doc.Content), doc.Content); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", doc.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateOutput validates a document serialized by AggregateDocuments against the schema of its version.
+func ValidateOutput(config types.ComponentsConfig, output string) error {
+	format, err := OutputFormat(config)
+	if err != nil {
+		return err
+	}
+	if err := schema.ValidateData(format, []byte(output)); err != nil {
+		return fmt.Errorf("generated component definition: %w", err)
+	}
+	return nil
+}
+
+// Finding is a single problem found in a component-definition file.
+type Finding struct {
+	File string
+	// Path is the JSONPath of the offending value, or $ for problems with the file as a whole
+	Path    string
+	Message string
+}
+
+func (f Finding) String() string {
+	return f.File + ": " + f.Path + ": " + f.Message
+}
+
+// CheckFile validates a component-definition file against its schema and checks the references between its parts.
+func CheckFile(name string, data []byte) []Finding {
+	finding := func(issue schema.Issue) Finding {
+		return Finding{File: name, Path: issue.Path, Message: issue.Message}
+	}
+
+	document, err := schema.Decode(oscal.DetectFormat(name, data), data)
+	if err != nil {
+		return []Finding{finding(schema.Issue{Path: "$", Message: err.Error()})}
+	}
+
+	var findings []Finding
+	// Semantic issues at a path the schema already reported, such as a malformed uuid, are left out
+	reported := map[string]bool{}
+	var schemaErr *schema.Error
+	if err := schema.Validate(document); errors.As(err, &schemaErr) {
+		for _, issue := range schemaErr.Issues {
+			reported[issue.Path] = true
+			findings = append(findings, finding(issue))
+		}
+	} else if err != nil {
+		findings = append(findings, finding(schema.Issue{Path: "$", Message: err.Error()}))
+	}
+	for _, issue := range semantic.Check(document) {
+		if !reported[issue.Path] {
+			findings = append(findings, finding(issue))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,42 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/cache"
+)
+
+// fetchCached serves a remote document from the cache when the source is immutable, matches its digest or is offline,
+// and otherwise fetches it and refreshes the cache.
+func fetchCached(src Source, opts Options, immutable bool, fetch func() (Document, error)) (Document, error) {
+	if opts.CacheDirectory == "" {
+		if opts.Offline {
+			return Document{}, fmt.Errorf("%s cannot be fetched in offline mode without a cache directory", src)
+		}
+		return fetch()
+	}
+
+	c := cache.New(opts.CacheDirectory)
+	key := src.Scheme + " " + src.String() + " " + src.MediaType
+
+	entry, ok, err := c.Get(key)
+	if err != nil {
+		return Document{}, err
+	}
+	if ok && (opts.Offline || immutable || strings.EqualFold(entry.Digest, src.Digest)) {
+		return Document{Source: src, Name: entry.Name, Content: entry.Content, Resolved: entry.Resolved}, nil
+	}
+	if opts.Offline {
+		return Document{}, fmt.Errorf("%s is not in the cache at %s and offline mode is enabled", src, opts.CacheDirectory)
+	}
+
+	doc, err := fetch()
+	if err != nil {
+		return Document{}, err
+	}
+	if err := c.Put(cache.Entry{Key: key, Name: doc.Name, Resolved: doc.Resolved, Content: doc.Content}); err != nil {
+		return Document{}, err
+	}
+	return doc, nil
+}
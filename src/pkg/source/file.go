@@ -0,0 +1,134 @@
+package source
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+)
+
+// FileScheme identifies sources read from the local filesystem.
+const FileScheme = "file"
+
+func init() {
+	Register(FileScheme, FetcherFunc(fetchFile))
+}
+
+// fetchFile reads a file, every file a glob pattern matches or every component-definition beneath a directory.
+func fetchFile(src Source, opts Options) ([]Document, error) {
+	resolve := func(name string) string {
+		if filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(opts.BaseDirectory, name)
+	}
+
+	if isGlob(src.Location) {
+		return fetchFiles(src, resolve, globFiles)
+	}
+	info, err := os.Stat(resolve(src.Location))
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return fetchFiles(src, resolve, directoryFiles)
+	}
+
+	content, err := os.ReadFile(resolve(src.Location))
+	if err != nil {
+		return nil, err
+	}
+	return []Document{{Source: src, Name: src.Location, Content: content}}, nil
+}
+
+// fetchFiles reads every file a glob pattern or directory expands to, named relative to the base directory.
+func fetchFiles(src Source, resolve func(string) string, expand func(root, pattern string) ([]string, error)) ([]Document, error) {
+	base, pattern := doublestar.SplitPattern(filepath.ToSlash(src.Location))
+	if !isGlob(src.Location) {
+		base, pattern = strings.TrimSuffix(filepath.ToSlash(src.Location), "/"), ""
+	}
+
+	matches, err := expand(resolve(filepath.FromSlash(base)), pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s: %w", src.Location, err)
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for _, match := range matches {
+		name := path.Join(base, match)
+		if seen[name] || isExcluded(src.Exclude, name) {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	documents := []Document{}
+	for _, name := range names {
+		content, err := os.ReadFile(resolve(filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		if pattern == "" && !oscal.IsComponentDefinition(content) {
+			continue
+		}
+		documents = append(documents, Document{Source: src, Name: name, Content: content})
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("no component definitions found in %s", src.Location)
+	}
+	return documents, nil
+}
+
+// globFiles returns the files under root matching a slash separated pattern.
+func globFiles(root, pattern string) ([]string, error) {
+	return doublestar.Glob(os.DirFS(root), pattern, doublestar.WithFilesOnly())
+}
+
+// directoryFiles returns every file beneath root, skipping hidden directories such as .git.
+func directoryFiles(root, _ string) ([]string, error) {
+	files := []string{}
+	err := fs.WalkDir(os.DirFS(root), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, name)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isGlob reports whether a location holds glob syntax rather than naming a single file or directory.
+func isGlob(location string) bool {
+	return strings.ContainsAny(location, "*?[{")
+}
+
+// isExcluded reports whether a file's path, or its name for patterns without '/', matches an exclude pattern.
+func isExcluded(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		target := name
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(name)
+		}
+		if matched, _ := doublestar.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package source
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/defenseunicorns/component-generator/src/internal/auth"
+	"github.com/defenseunicorns/component-generator/src/internal/git"
+)
+
+// GitScheme identifies sources read from a file in a git repository.
+const GitScheme = "git"
+
+func init() {
+	Register(GitScheme, FetcherFunc(fetchGit))
+}
+
+// commitPattern matches a full commit SHA, which unlike a tag or branch can never point at different content.
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// fetchGit reads a document from a git repository, named after the tag a constraint resolved to.
+func fetchGit(src Source, opts Options) ([]Document, error) {
+	doc, err := fetchCached(src, opts, commitPattern.MatchString(src.Ref), func() (Document, error) {
+		authorization := ""
+		// Only HTTP(S) remotes take a header - ssh remotes authenticate with keys
+		if u, err := url.Parse(src.Location); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+			cred, ok, err := auth.Lookup(u.Hostname(), opts.Auth)
+			if err != nil {
+				return Document{}, err
+			}
+			if ok {
+				authorization = cred.GitHeader()
+			}
+		}
+
+		resolved := src
+		if git.IsConstraint(src.Ref) {
+			tag, err := git.ResolveConstraint(src.Location, src.Ref, authorization)
+			if err != nil {
+				return Document{}, err
+			}
+			resolved.Ref = tag
+		}
+
+		content, commit, err := git.FetchFile(src.Location, resolved.Ref, src.Path, authorization)
+		if err != nil {
+			return Document{}, err
+		}
+		return Document{Source: src, Name: resolved.String(), Content: content, Resolved: commit}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Document{doc}, nil
+}
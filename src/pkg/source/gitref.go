@@ -0,0 +1,80 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitRefSyntax is shown in errors about malformed git references.
+const gitRefSyntax = "REPO[.git]//PATH@REF"
+
+// ParseGitReference parses a reference to a file in a git repository into a Source. The accepted forms are:
+//
+//	REPO[.git]//PATH@REF                  https://repo1.dso.mil/big-bang/apps/core/kiali.git//oscal-component.yaml@1.60.0-bb.2
+//	REPO.git/PATH@REF                     https://repo1.dso.mil/big-bang/apps/core/kiali.git/oscal-component.yaml@1.60.0-bb.2
+//	github.com/ORG/REPO[/PATH]@REF        github.com/defenseunicorns/terraform-aws-uds-s3/oscal-component.yaml@v0.0.3
+//	REPO@REF                              git@github.com:defenseunicorns/terraform-aws-uds-s3.git@v0.0.3
+//
+// The path is optional, as declarative entries supply it separately, but the ref is required.
+func ParseGitReference(ref string) (Source, error) {
+	src := Source{Scheme: GitScheme}
+
+	location, gitRef, ok := splitGitRef(ref)
+	if !ok {
+		return src, fmt.Errorf("remote git URL must specify a git ref using the following syntax: 'https://github.com/<org>/<repo>@<git ref>' or '%s' - got %q", gitRefSyntax, ref)
+	}
+	src.Ref = gitRef
+
+	// The scheme separator is not a path separator
+	schemeEnd := 0
+	if i := strings.Index(location, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+
+	switch rest := location[schemeEnd:]; {
+	case strings.Contains(rest, "//"):
+		i := schemeEnd + strings.Index(rest, "//")
+		src.Location, src.Path = location[:i], location[i+len("//"):]
+		if src.Path == "" {
+			return src, fmt.Errorf("git reference %q has an empty path - expected %s", ref, gitRefSyntax)
+		}
+	case strings.Contains(rest, ".git/"):
+		i := schemeEnd + strings.Index(rest, ".git/")
+		src.Location, src.Path = location[:i+len(".git")], location[i+len(".git/"):]
+	case strings.HasPrefix(rest, "github.com/") && (schemeEnd == 0 || strings.HasPrefix(location, "https://")):
+		segments := strings.SplitN(strings.TrimPrefix(rest, "github.com/"), "/", 3)
+		if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+			return src, fmt.Errorf("github reference %q must be of the form github.com/<org>/<repo>[/<path>]@<ref>", ref)
+		}
+		src.Location = "https://github.com/" + segments[0] + "/" + segments[1]
+		if len(segments) == 3 {
+			src.Path = segments[2]
+		}
+	default:
+		src.Location = location
+	}
+
+	if src.Location == "" || strings.HasSuffix(src.Location, "://") {
+		return src, fmt.Errorf("git reference %q must include a repository - expected %s", ref, gitRefSyntax)
+	}
+	return src, nil
+}
+
+// splitGitRef separates a trailing `@REF`, which cannot contain ':', so that the user of an ssh remote is not
+// mistaken for one.
+func splitGitRef(ref string) (string, string, bool) {
+	at := strings.LastIndex(ref, "@")
+	if at < 0 {
+		return ref, "", false
+	}
+	location, gitRef := ref[:at], ref[at+1:]
+
+	if gitRef == "" || strings.Contains(gitRef, ":") {
+		return ref, "", false
+	}
+	// https://user@host/repo - the '@' is inside the authority
+	if i := strings.Index(location, "://"); i >= 0 && !strings.Contains(location[i+len("://"):], "/") {
+		return ref, "", false
+	}
+	return location, gitRef, true
+}
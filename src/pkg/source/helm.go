@@ -0,0 +1,104 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/digest"
+	"github.com/defenseunicorns/component-generator/src/internal/helm"
+)
+
+const (
+	// HelmScheme identifies sources read from a chart directory or packaged chart on disk.
+	HelmScheme = "helm"
+	// HelmRepoScheme identifies sources read from a chart repository, located by REPO_URL/CHART.
+	HelmRepoScheme = "helm-repo"
+)
+
+func init() {
+	Register(HelmScheme, FetcherFunc(fetchHelm))
+	Register(HelmRepoScheme, FetcherFunc(fetchHelmRepo))
+}
+
+// fetchHelm reads a file from a chart directory or packaged chart, whose version must match the source ref if set.
+func fetchHelm(src Source, opts Options) ([]Document, error) {
+	chartPath := src.Location
+	if !filepath.IsAbs(chartPath) {
+		chartPath = filepath.Join(opts.BaseDirectory, chartPath)
+	}
+
+	content, chart, err := helm.ReadChartFile(chartPath, src.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkChartVersion(src, chart); err != nil {
+		return nil, err
+	}
+	return []Document{{Source: src, Name: src.String(), Content: content}}, nil
+}
+
+// fetchHelmRepo downloads a chart version from a chart repository, verified by its index digest, and reads a file.
+func fetchHelmRepo(src Source, opts Options) ([]Document, error) {
+	if src.Ref == "" {
+		return nil, fmt.Errorf("chart %s must specify a version", src.Location)
+	}
+	slash := strings.LastIndex(src.Location, "/")
+	if slash < 0 {
+		return nil, fmt.Errorf("chart repository location %q must be of the form REPO_URL/CHART", src.Location)
+	}
+	repo, name := src.Location[:slash], src.Location[slash+1:]
+
+	doc, err := fetchCached(src, opts, false, func() (Document, error) {
+		index, err := getHTTP(repo+"/index.yaml", opts)
+		if err != nil {
+			return Document{}, err
+		}
+		entry, err := helm.FindInIndex(index, name, src.Ref)
+		if err != nil {
+			return Document{}, fmt.Errorf("%s: %w", repo, err)
+		}
+
+		// Chart URLs may be relative to the repository
+		base, err := url.Parse(repo + "/")
+		if err != nil {
+			return Document{}, err
+		}
+		chartURL, err := base.Parse(entry.URLs[0])
+		if err != nil {
+			return Document{}, fmt.Errorf("invalid chart URL %q: %w", entry.URLs[0], err)
+		}
+		packaged, err := getHTTP(chartURL.String(), opts)
+		if err != nil {
+			return Document{}, err
+		}
+		// Repository indexes record the sha256 of a chart without an algorithm prefix
+		if entry.Digest != "" {
+			if err := digest.Verify(packaged, "sha256:"+strings.TrimPrefix(entry.Digest, "sha256:")); err != nil {
+				return Document{}, fmt.Errorf("%s: %w", chartURL, err)
+			}
+		}
+
+		content, chart, err := helm.ReadPackagedChartFile(bytes.NewReader(packaged), src.Path)
+		if err != nil {
+			return Document{}, err
+		}
+		if err := checkChartVersion(src, chart); err != nil {
+			return Document{}, err
+		}
+		return Document{Source: src, Name: src.String(), Content: content, Resolved: chartURL.String()}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Document{doc}, nil
+}
+
+func checkChartVersion(src Source, chart helm.Chart) error {
+	if src.Ref != "" && chart.Version != src.Ref {
+		return fmt.Errorf("chart %s is version %s, expected %s", chart.Name, chart.Version, src.Ref)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"net/url"
+	"path/filepath"
+
+	"github.com/defenseunicorns/component-generator/src/internal/auth"
+	"github.com/defenseunicorns/component-generator/src/internal/oci"
+)
+
+const (
+	// OCIScheme identifies sources pulled from an OCI registry.
+	OCIScheme = "oci"
+	// OCILayoutScheme identifies sources read from an OCI image layout directory on disk.
+	OCILayoutScheme = "oci-layout"
+)
+
+func init() {
+	Register(OCIScheme, FetcherFunc(fetchOCI))
+	Register(OCILayoutScheme, FetcherFunc(fetchOCILayout))
+}
+
+// fetchOCI pulls the component-definition layer from the image reference held in the source location.
+func fetchOCI(src Source, opts Options) ([]Document, error) {
+	ref, err := oci.ParseReference(src.Location)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := fetchCached(src, opts, ref.Digest != "", func() (Document, error) {
+		// Credentials are keyed by host name, without the port of a registry such as localhost:5000
+		cred, ok, err := auth.Lookup((&url.URL{Host: ref.Registry}).Hostname(), opts.Auth)
+		if err != nil {
+			return Document{}, err
+		}
+		pullOpts := oci.PullOptions{HTTP: opts.httpClient()}
+		if ok {
+			pullOpts.Credential = &cred
+		}
+		content, manifestDigest, err := oci.Pull(ref, ociMediaType(src), pullOpts)
+		if err != nil {
+			return Document{}, err
+		}
+		return Document{Source: src, Name: src.String(), Content: content, Resolved: manifestDigest}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Document{doc}, nil
+}
+
+// fetchOCILayout reads the component-definition layer of a manifest in an OCI image layout directory.
+func fetchOCILayout(src Source, opts Options) ([]Document, error) {
+	dir := src.Location
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(opts.BaseDirectory, dir)
+	}
+	content, manifestDigest, err := oci.PullFromLayout(dir, src.Ref, ociMediaType(src))
+	if err != nil {
+		return nil, err
+	}
+	return []Document{{Source: src, Name: src.String(), Content: content, Resolved: manifestDigest}}, nil
+}
+
+func ociMediaType(src Source) string {
+	if src.MediaType != "" {
+		return src.MediaType
+	}
+	return oci.DefaultMediaType
+}
@@ -0,0 +1,263 @@
+package source
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/component-generator/src/internal/digest"
+	"github.com/defenseunicorns/component-generator/src/internal/http"
+	"github.com/defenseunicorns/component-generator/src/internal/oci"
+	"github.com/defenseunicorns/component-generator/src/internal/types"
+)
+
+// Source identifies a single component-definition source declared in a components config.
+type Source struct {
+	// Scheme selects the Fetcher used to retrieve the source - e.g. file, git, https
+	Scheme string
+	// Location is the scheme specific address of the source - a file path, repository or URI
+	Location string
+	// Path is the location of the document within the source, if the source holds more than one file
+	Path string
+	// Ref is the revision of the source to retrieve - e.g. a git tag, branch or commit
+	Ref string
+	// MediaType selects the document within a source that holds typed content, such as an OCI artifact
+	MediaType string
+	// Digest is the expected digest of the fetched content, of the form <algorithm>:<hex>
+	Digest string
+	// Exclude lists patterns of files to skip when a source expands to several files
+	Exclude []string
+	// Imported marks a source imported by another document, which may omit a digest as OSCAL imports carry none
+	Imported bool
+}
+
+func (s Source) String() string {
+	str := s.Location
+	if s.Path != "" {
+		str += "//" + s.Path
+	}
+	if s.Ref != "" {
+		str += "@" + s.Ref
+	}
+	return str
+}
+
+// schemePattern matches a URI scheme, as defined by RFC 3986.
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+// Parse converts a URI into a Source. The built-in schemes map the rest of the URI onto the fields of the source:
+//
+//	file:PATH                                  file:///etc/oscal/oscal-component.yaml
+//	git[+TRANSPORT]://REPO//PATH@REF           git+https://github.com/org/repo.git//oscal-component.yaml@v1.0.0
+//	oci://REGISTRY/REPOSITORY[:TAG][@DIGEST]   oci://ghcr.io/org/component:1.0.0
+//	oci-layout:DIR[@REF]                       oci-layout:./layout@1.0.0
+//	zarf:PACKAGE[//PATTERN]                    zarf:./zarf-package-kiali-amd64.tar.zst//oscal-component.yaml
+//	helm:CHART[//PATH][@VERSION]               helm:./charts/kiali@1.60.0
+//	helm-repo:REPO_URL/CHART[//PATH]@VERSION   helm-repo:https://charts.example.com/kiali@1.60.0
+//
+// Local paths may also be absolute file URIs. Any other scheme keeps the full URI as the location.
+func Parse(uri string) (Source, error) {
+	scheme := Scheme(uri)
+	if scheme == "" {
+		return Source{}, fmt.Errorf("source URI %q must include a scheme", uri)
+	}
+	rest := uri[len(scheme)+1:]
+
+	if transport, ok := strings.CutPrefix(scheme, GitScheme+"+"); ok {
+		return ParseGitReference(transport + ":" + rest)
+	}
+
+	switch scheme {
+	case GitScheme:
+		return ParseGitReference(uri)
+	case OCIScheme:
+		location := strings.TrimPrefix(rest, "//")
+		if _, err := oci.ParseReference(location); err != nil {
+			return Source{}, err
+		}
+		return Source{Scheme: scheme, Location: location}, nil
+	case HelmRepoScheme:
+		location, ref := splitRef(rest)
+		schemeEnd := strings.Index(location, "://")
+		if ref == "" || schemeEnd < 0 {
+			return Source{}, fmt.Errorf("source URI %q must be of the form %s:REPO_URL/CHART[//PATH]@VERSION", uri, scheme)
+		}
+		location, path := splitPath(location, schemeEnd+len("://"))
+		return Source{Scheme: scheme, Location: location, Path: path, Ref: ref}, nil
+	case FileScheme, OCILayoutScheme, ZarfScheme, HelmScheme:
+	default:
+		return Source{Scheme: scheme, Location: uri}, nil
+	}
+
+	location, err := localPath(uri, rest)
+	if err != nil {
+		return Source{}, err
+	}
+	src := Source{Scheme: scheme}
+	switch scheme {
+	case OCILayoutScheme:
+		location, src.Ref = splitRef(location)
+	case ZarfScheme:
+		location, src.Path = splitPath(location, 0)
+	case HelmScheme:
+		location, src.Ref = splitRef(location)
+		location, src.Path = splitPath(location, 0)
+	}
+	src.Location = filepath.FromSlash(location)
+	return src, nil
+}
+
+// Scheme returns the lower-cased scheme of a URI, or an empty string when it has none.
+func Scheme(uri string) string {
+	scheme, _, ok := strings.Cut(uri, ":")
+	if !ok || !schemePattern.MatchString(scheme) {
+		return ""
+	}
+	return strings.ToLower(scheme)
+}
+
+// localPath returns the path of SCHEME:PATH, SCHEME:///PATH or SCHEME://localhost/PATH.
+func localPath(uri, rest string) (string, error) {
+	authority, ok := strings.CutPrefix(rest, "//")
+	if !ok {
+		return rest, nil
+	}
+	host, path, ok := strings.Cut(authority, "/")
+	if !ok || (host != "" && host != "localhost") {
+		return "", fmt.Errorf("source URI %q must name a local path - expected SCHEME:PATH or SCHEME:///ABSOLUTE_PATH", uri)
+	}
+	return "/" + path, nil
+}
+
+// splitRef separates a trailing @REF, which cannot contain a '/', from a location.
+func splitRef(location string) (string, string) {
+	at := strings.LastIndex(location, "@")
+	if at < 0 || at < strings.LastIndex(location, "/") {
+		return location, ""
+	}
+	return location[:at], location[at+1:]
+}
+
+// splitPath separates a //PATH found at or after the given offset from a location.
+func splitPath(location string, offset int) (string, string) {
+	i := strings.Index(location[offset:], "//")
+	if i < 0 {
+		return location, ""
+	}
+	return location[:offset+i], location[offset+i+len("//"):]
+}
+
+// Document is the raw content of a component-definition retrieved from a Source.
+type Document struct {
+	Source Source
+	// Name identifies the specific file the content was read from
+	Name    string
+	Content []byte
+	// Resolved is the immutable identity the source resolved to, such as a git commit SHA or a manifest digest
+	Resolved string
+}
+
+// Options holds the settings that apply to every Fetcher during a single aggregation.
+type Options struct {
+	// BaseDirectory is the directory relative file paths are resolved against
+	BaseDirectory string
+	// CacheDirectory is where remote documents are cached. Caching is disabled when it is empty
+	CacheDirectory string
+	// Offline serves remote documents only from the cache, failing on a miss
+	Offline bool
+	// Auth supplies credentials for remote hosts, ahead of the environment and netrc
+	Auth []types.Auth
+	// Timeout limits each HTTP request, defaulting to 10 seconds when zero
+	Timeout time.Duration
+	// Retries is the number of times an HTTP request that failed transiently is retried
+	Retries int
+}
+
+// httpClient returns the HTTP client configured by the options.
+func (o Options) httpClient() http.Client {
+	client := http.DefaultClient
+	client.Retries = o.Retries
+	if o.Timeout > 0 {
+		client.Timeout = o.Timeout
+	}
+	return client
+}
+
+// Fetcher retrieves the component-definition document(s) identified by a Source.
+type Fetcher interface {
+	Fetch(src Source, opts Options) ([]Document, error)
+}
+
+// FetcherFunc adapts an ordinary function to the Fetcher interface.
+type FetcherFunc func(src Source, opts Options) ([]Document, error)
+
+func (f FetcherFunc) Fetch(src Source, opts Options) ([]Document, error) {
+	return f(src, opts)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Fetcher{}
+)
+
+// Register makes a Fetcher available for a scheme, replacing any registered before. A nil Fetcher unregisters it.
+func Register(scheme string, fetcher Fetcher) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if fetcher == nil {
+		delete(registry, scheme)
+		return
+	}
+	registry[scheme] = fetcher
+}
+
+// Lookup returns the Fetcher registered for the given scheme.
+func Lookup(scheme string) (Fetcher, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	fetcher, ok := registry[scheme]
+	if !ok {
+		return nil, fmt.Errorf("no fetcher is registered for scheme %q", scheme)
+	}
+	return fetcher, nil
+}
+
+// Schemes returns the sorted list of registered schemes.
+func Schemes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Fetch retrieves the documents of a Source with the Fetcher of its scheme, verifying them against its digest.
+func Fetch(src Source, opts Options) ([]Document, error) {
+	fetcher, err := Lookup(src.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := fetcher.Fetch(src, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Digest != "" {
+		for _, doc := range documents {
+			if err := digest.Verify(doc.Content, src.Digest); err != nil {
+				return nil, fmt.Errorf("integrity check failed for %s: %w", doc.Name, err)
+			}
+		}
+	}
+	return documents, nil
+}
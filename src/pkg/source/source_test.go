@@ -0,0 +1,475 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/defenseunicorns/component-generator/src/internal/oci"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for uri, expected := range map[string]Source{
+		"example://registry/component-definition":            {Scheme: "example", Location: "example://registry/component-definition"},
+		"https://example.com/oscal-component.yaml":           {Scheme: "https", Location: "https://example.com/oscal-component.yaml"},
+		"file:components/oscal-component.yaml":               {Scheme: FileScheme, Location: filepath.FromSlash("components/oscal-component.yaml")},
+		"file:///etc/oscal/oscal-component.yaml":             {Scheme: FileScheme, Location: filepath.FromSlash("/etc/oscal/oscal-component.yaml")},
+		"file://localhost/etc/oscal/oscal-component.yaml":    {Scheme: FileScheme, Location: filepath.FromSlash("/etc/oscal/oscal-component.yaml")},
+		"git://example.com/org/repo.git//oscal.yaml@v1.0.0":  {Scheme: GitScheme, Location: "git://example.com/org/repo.git", Path: "oscal.yaml", Ref: "v1.0.0"},
+		"git+https://example.com/org/repo.git//a.yaml@v1":    {Scheme: GitScheme, Location: "https://example.com/org/repo.git", Path: "a.yaml", Ref: "v1"},
+		"git+file:///srv/git/repo.git//a.yaml@main":          {Scheme: GitScheme, Location: "file:///srv/git/repo.git", Path: "a.yaml", Ref: "main"},
+		"oci://ghcr.io/org/component:1.0.0":                  {Scheme: OCIScheme, Location: "ghcr.io/org/component:1.0.0"},
+		"oci-layout:layout@1.0.0":                            {Scheme: OCILayoutScheme, Location: "layout", Ref: "1.0.0"},
+		"zarf:packages/zarf-package-kiali.tar.zst//*.yaml":   {Scheme: ZarfScheme, Location: filepath.FromSlash("packages/zarf-package-kiali.tar.zst"), Path: "*.yaml"},
+		"zarf:///tmp/zarf-package-kiali.tar.zst":             {Scheme: ZarfScheme, Location: filepath.FromSlash("/tmp/zarf-package-kiali.tar.zst")},
+		"helm:charts/kiali//oscal.yaml@1.60.0-bb.2":          {Scheme: HelmScheme, Location: filepath.FromSlash("charts/kiali"), Path: "oscal.yaml", Ref: "1.60.0-bb.2"},
+		"helm:kiali-1.60.0-bb.2.tgz":                         {Scheme: HelmScheme, Location: "kiali-1.60.0-bb.2.tgz"},
+		"helm-repo:https://charts.example.com/kiali@1.60.0":  {Scheme: HelmRepoScheme, Location: "https://charts.example.com/kiali", Ref: "1.60.0"},
+		"helm-repo:https://example.com/kiali//a.yaml@1.60.0": {Scheme: HelmRepoScheme, Location: "https://example.com/kiali", Path: "a.yaml", Ref: "1.60.0"},
+	} {
+		src, err := Parse(uri)
+		require.NoError(t, err, uri)
+		require.Equal(t, expected, src, uri)
+	}
+
+	for uri, expected := range map[string]string{
+		"no-scheme/component-definition":          "must include a scheme",
+		"file://example.com/oscal-component.yaml": "must name a local path",
+		"oci://component:1.0.0":                   "must be of the form REGISTRY/REPOSITORY",
+		"git://example.com/org/repo.git":          "must specify a git ref",
+		"helm-repo:https://example.com/kiali":     "must be of the form helm-repo:REPO_URL/CHART[//PATH]@VERSION",
+	} {
+		_, err := Parse(uri)
+		require.ErrorContains(t, err, expected, uri)
+	}
+}
+
+func TestRegisterAndFetch(t *testing.T) {
+	t.Parallel()
+
+	scheme := "test-register-and-fetch"
+	Register(scheme, FetcherFunc(func(src Source, _ Options) ([]Document, error) {
+		return []Document{{Source: src, Name: src.Location, Content: []byte("fetched")}}, nil
+	}))
+	t.Cleanup(func() { Register(scheme, nil) })
+
+	require.Contains(t, Schemes(), scheme)
+
+	documents, err := Fetch(Source{Scheme: scheme, Location: "anything"}, Options{})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Equal(t, "fetched", string(documents[0].Content))
+
+	_, err = Fetch(Source{Scheme: "unregistered"}, Options{})
+	require.ErrorContains(t, err, `no fetcher is registered for scheme "unregistered"`)
+}
+
+func TestFetchFile(t *testing.T) {
+	t.Parallel()
+
+	documents, err := Fetch(Source{Scheme: FileScheme, Location: "jaeger-component-definition.yaml"}, Options{BaseDirectory: "../../../testdata/input/"})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Contains(t, string(documents[0].Content), "component-definition")
+}
+
+func TestFetchFileExpansion(t *testing.T) {
+	t.Parallel()
+
+	componentDefinition := []byte("component-definition:\n  uuid: 1\n")
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"components/b/oscal-b.yaml":          componentDefinition,
+		"components/a/oscal-a.yaml":          componentDefinition,
+		"components/a/values.yaml":           []byte("replicas: 1\n"),
+		"components/legacy/oscal-old.yaml":   componentDefinition,
+		"components/.git/oscal-hidden.yaml":  componentDefinition,
+		"components/oscal-top.yaml":          componentDefinition,
+		"components/legacy/oscal-other.yaml": componentDefinition,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+
+	names := func(documents []Document) []string {
+		result := []string{}
+		for _, doc := range documents {
+			result = append(result, doc.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		src      Source
+		expected []string
+	}{
+		{
+			name:     "glob",
+			src:      Source{Location: "components/**/oscal-*.yaml"},
+			expected: []string{"components/.git/oscal-hidden.yaml", "components/a/oscal-a.yaml", "components/b/oscal-b.yaml", "components/legacy/oscal-old.yaml", "components/legacy/oscal-other.yaml", "components/oscal-top.yaml"},
+		},
+		{
+			name:     "overlapping alternatives are deduplicated",
+			src:      Source{Location: "components/{a,*}/oscal-*.yaml"},
+			expected: []string{"components/.git/oscal-hidden.yaml", "components/a/oscal-a.yaml", "components/b/oscal-b.yaml", "components/legacy/oscal-old.yaml", "components/legacy/oscal-other.yaml"},
+		},
+		{
+			name:     "directory skips hidden directories and other files",
+			src:      Source{Location: "components/"},
+			expected: []string{"components/a/oscal-a.yaml", "components/b/oscal-b.yaml", "components/legacy/oscal-old.yaml", "components/legacy/oscal-other.yaml", "components/oscal-top.yaml"},
+		},
+		{
+			name:     "exclude",
+			src:      Source{Location: "components", Exclude: []string{"components/legacy/**", "oscal-top.yaml"}},
+			expected: []string{"components/a/oscal-a.yaml", "components/b/oscal-b.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.src.Scheme = FileScheme
+			documents, err := Fetch(tt.src, Options{BaseDirectory: dir})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, names(documents))
+		})
+	}
+
+	_, err := Fetch(Source{Scheme: FileScheme, Location: "components/**/*.json"}, Options{BaseDirectory: dir})
+	require.ErrorContains(t, err, "no component definitions found in components/**/*.json")
+}
+
+func TestFetchURL(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("component-definition")
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oscal-component.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	src, err := Parse(server.URL + "/oscal-component.yaml")
+	require.NoError(t, err)
+	src.Digest = digest
+
+	documents, err := Fetch(src, Options{})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Equal(t, content, documents[0].Content)
+
+	src.Digest = ""
+	_, err = Fetch(src, Options{})
+	require.ErrorContains(t, err, "must specify a checksum")
+
+	missing, err := Parse(server.URL + "/missing.yaml")
+	require.NoError(t, err)
+	missing.Digest = digest
+	_, err = Fetch(missing, Options{})
+	require.ErrorContains(t, err, "unexpected response code")
+}
+
+func TestFetchCached(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("component-definition")
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	src, err := Parse(server.URL + "/oscal-component.yaml")
+	require.NoError(t, err)
+	src.Digest = digest
+	opts := Options{CacheDirectory: t.TempDir(), Offline: true}
+
+	_, err = Fetch(src, opts)
+	require.ErrorContains(t, err, "is not in the cache")
+	require.Equal(t, 0, requests)
+
+	// Populates the cache
+	opts.Offline = false
+	_, err = Fetch(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// The cached content already matches the expected digest so the server is not contacted again
+	documents, err := Fetch(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, content, documents[0].Content)
+	require.Equal(t, 1, requests)
+
+	opts.Offline = true
+	documents, err = Fetch(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, content, documents[0].Content)
+	require.Equal(t, 1, requests)
+}
+
+func TestParseGitReference(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected Source
+		err      string
+	}{
+		{
+			input:    "https://repo1.dso.mil/big-bang/apps/core/kiali.git//oscal-component.yaml@1.60.0-bb.2",
+			expected: Source{Location: "https://repo1.dso.mil/big-bang/apps/core/kiali.git", Path: "oscal-component.yaml", Ref: "1.60.0-bb.2"},
+		},
+		{
+			input:    "https://repo1.dso.mil/big-bang/apps/core/kiali.git/docs/oscal-component.yaml@1.60.0-bb.2",
+			expected: Source{Location: "https://repo1.dso.mil/big-bang/apps/core/kiali.git", Path: "docs/oscal-component.yaml", Ref: "1.60.0-bb.2"},
+		},
+		{
+			input:    "https://gitea.example.com/org/repo//nested/oscal-component.yaml@feature/new",
+			expected: Source{Location: "https://gitea.example.com/org/repo", Path: "nested/oscal-component.yaml", Ref: "feature/new"},
+		},
+		{
+			input:    "github.com/defenseunicorns/terraform-aws-uds-s3/oscal-component.yaml@v0.0.3",
+			expected: Source{Location: "https://github.com/defenseunicorns/terraform-aws-uds-s3", Path: "oscal-component.yaml", Ref: "v0.0.3"},
+		},
+		{
+			input:    "https://github.com/defenseunicorns/terraform-aws-uds-s3@v0.0.3",
+			expected: Source{Location: "https://github.com/defenseunicorns/terraform-aws-uds-s3", Ref: "v0.0.3"},
+		},
+		{
+			input:    "git@github.com:defenseunicorns/terraform-aws-uds-s3.git//oscal-component.yaml@~0.0.1",
+			expected: Source{Location: "git@github.com:defenseunicorns/terraform-aws-uds-s3.git", Path: "oscal-component.yaml", Ref: "~0.0.1"},
+		},
+		{
+			input:    "file:///srv/git/repo.git//oscal-component.yaml@main",
+			expected: Source{Location: "file:///srv/git/repo.git", Path: "oscal-component.yaml", Ref: "main"},
+		},
+		{input: "https://repo1.dso.mil/big-bang/apps/core/kiali.git/oscal-component.yaml", err: "must specify a git ref"},
+		{input: "git@github.com:defenseunicorns/terraform-aws-uds-s3.git", err: "must specify a git ref"},
+		{input: "https://user@example.com/repo.git", err: "must specify a git ref"},
+		{input: "https://example.com/repo.git//@v1", err: "has an empty path"},
+		{input: "github.com/defenseunicorns@v1", err: "must be of the form github.com/<org>/<repo>"},
+		{input: "@v1", err: "must include a repository"},
+	}
+
+	for _, testCase := range testCases {
+		src, err := ParseGitReference(testCase.input)
+		if testCase.err != "" {
+			require.ErrorContains(t, err, testCase.err, testCase.input)
+			require.ErrorContains(t, err, testCase.input, testCase.input)
+			continue
+		}
+		require.NoError(t, err, testCase.input)
+		testCase.expected.Scheme = GitScheme
+		require.Equal(t, testCase.expected, src, testCase.input)
+	}
+}
+
+// tarball builds an uncompressed tar archive holding the given files.
+func tarball(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// ociRegistry serves content as the component-definition layer of repository:tag, requiring authorization if set.
+func ociRegistry(t *testing.T, repository, tag, content, authorization string) *httptest.Server {
+	t.Helper()
+
+	blobs := map[string][]byte{}
+	add := func(content []byte) string {
+		sum := sha256.Sum256(content)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		blobs[digest] = content
+		return digest
+	}
+	manifest, err := json.Marshal(map[string]interface{}{
+		"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"layers":    []map[string]interface{}{{"mediaType": oci.DefaultMediaType, "digest": add([]byte(content)), "size": len(content)}},
+	})
+	require.NoError(t, err)
+	manifestDigest := add(manifest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorization != "" && r.Header.Get("Authorization") != authorization {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/v2/"+repository+"/")
+		if path == "manifests/"+tag {
+			path = "manifests/" + manifestDigest
+		}
+		_, digest, _ := strings.Cut(path, "/")
+		blob, ok := blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(blob)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestFetchOCI checks that the credential of a registry is found by its host name, without the port.
+func TestFetchOCI(t *testing.T) {
+	componentDefinition := "component-definition:\n  uuid: 1\n"
+	server := ociRegistry(t, "org/component", "1.0.0", componentDefinition, "Basic "+base64.StdEncoding.EncodeToString([]byte("robot:s3cret")))
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte("machine 127.0.0.1 login robot password s3cret\n"), 0600))
+	t.Setenv("NETRC", netrc)
+
+	src, err := Parse("oci://" + strings.TrimPrefix(server.URL, "http://") + "/org/component:1.0.0")
+	require.NoError(t, err)
+	documents, err := Fetch(src, Options{})
+	require.NoError(t, err)
+	require.Equal(t, componentDefinition, string(documents[0].Content))
+	require.True(t, strings.HasPrefix(documents[0].Resolved, "sha256:"))
+
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	_, err = Fetch(src, Options{})
+	require.ErrorContains(t, err, "registry requires credentials")
+}
+
+func TestFetchZarf(t *testing.T) {
+	t.Parallel()
+
+	componentDefinition := []byte("component-definition:\n  uuid: 1\n")
+	component := tarball(t, map[string][]byte{
+		"kiali/files/0/oscal-component.yaml": componentDefinition,
+		"kiali/manifests/deployment.yaml":    []byte("kind: Deployment\n"),
+	})
+	pkg := tarball(t, map[string][]byte{
+		"zarf.yaml":                        []byte("kind: ZarfPackageConfig\n"),
+		"components/kiali.tar":             component,
+		"components/monitoring/oscal.json": []byte(`{"component-definition": {"uuid": "2"}}`),
+		"images/blobs/sha256/abc":          componentDefinition,
+	})
+
+	var compressed bytes.Buffer
+	zw, err := zstd.NewWriter(&compressed)
+	require.NoError(t, err)
+	_, err = zw.Write(pkg)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf-package-test-amd64-1.0.0.tar.zst"), compressed.Bytes(), 0644))
+
+	src := Source{Scheme: ZarfScheme, Location: "zarf-package-test-amd64-1.0.0.tar.zst"}
+	documents, err := Fetch(src, Options{BaseDirectory: dir})
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+	require.Equal(t, "zarf-package-test-amd64-1.0.0.tar.zst:components/kiali.tar/kiali/files/0/oscal-component.yaml", documents[0].Name)
+	require.Equal(t, componentDefinition, documents[0].Content)
+	require.Equal(t, "zarf-package-test-amd64-1.0.0.tar.zst:components/monitoring/oscal.json", documents[1].Name)
+
+	src.Path = "oscal-component.yaml"
+	documents, err = Fetch(src, Options{BaseDirectory: dir})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	src.Path = "missing.yaml"
+	_, err = Fetch(src, Options{BaseDirectory: dir})
+	require.ErrorContains(t, err, "no OSCAL component definitions found")
+}
+
+func TestFetchHelm(t *testing.T) {
+	t.Parallel()
+
+	componentDefinition := []byte("component-definition:\n  uuid: 1\n")
+	chartFile := []byte("apiVersion: v2\nname: kiali\nversion: 1.60.0-bb.2\n")
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "kiali"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kiali", "Chart.yaml"), chartFile, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kiali", "oscal-component.yaml"), componentDefinition, 0644))
+
+	var packaged bytes.Buffer
+	zw := gzip.NewWriter(&packaged)
+	_, err := zw.Write(tarball(t, map[string][]byte{
+		"kiali/Chart.yaml":           chartFile,
+		"kiali/oscal-component.yaml": componentDefinition,
+		"kiali/charts/dep-1.0.0.tgz": []byte("not searched"),
+	}))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kiali-1.60.0-bb.2.tgz"), packaged.Bytes(), 0644))
+
+	for _, location := range []string{"kiali", "kiali-1.60.0-bb.2.tgz"} {
+		documents, err := Fetch(Source{Scheme: HelmScheme, Location: location, Ref: "1.60.0-bb.2"}, Options{BaseDirectory: dir})
+		require.NoError(t, err, location)
+		require.Equal(t, componentDefinition, documents[0].Content, location)
+
+		_, err = Fetch(Source{Scheme: HelmScheme, Location: location, Ref: "1.59.0"}, Options{BaseDirectory: dir})
+		require.ErrorContains(t, err, "chart kiali is version 1.60.0-bb.2, expected 1.59.0", location)
+
+		_, err = Fetch(Source{Scheme: HelmScheme, Location: location, Path: "missing.yaml"}, Options{BaseDirectory: dir})
+		require.Error(t, err, location)
+	}
+
+	sum := sha256.Sum256(packaged.Bytes())
+	index := "apiVersion: v1\nentries:\n  kiali:\n  - version: 1.60.0-bb.2\n    urls: [kiali-1.60.0-bb.2.tgz]\n    digest: " + hex.EncodeToString(sum[:]) + "\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/charts/index.yaml":
+			_, _ = w.Write([]byte(index))
+		case "/charts/kiali-1.60.0-bb.2.tgz":
+			_, _ = w.Write(packaged.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	src := Source{Scheme: HelmRepoScheme, Location: server.URL + "/charts/kiali", Ref: "1.60.0-bb.2"}
+	documents, err := Fetch(src, Options{CacheDirectory: t.TempDir()})
+	require.NoError(t, err)
+	require.Equal(t, componentDefinition, documents[0].Content)
+	require.Equal(t, server.URL+"/charts/kiali-1.60.0-bb.2.tgz", documents[0].Resolved)
+
+	src.Ref = "1.59.0"
+	_, err = Fetch(src, Options{CacheDirectory: t.TempDir()})
+	require.ErrorContains(t, err, "chart kiali has no version 1.59.0 in the repository index")
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/defenseunicorns/component-generator/src/internal/auth"
+)
+
+func init() {
+	Register("https", FetcherFunc(fetchURL))
+	Register("http", FetcherFunc(fetchURL))
+}
+
+// fetchURL downloads a document from a URL, which must carry a digest unless another document imported it.
+func fetchURL(src Source, opts Options) ([]Document, error) {
+	if src.Digest == "" && !src.Imported {
+		return nil, fmt.Errorf("url source %s must specify a checksum", src.Location)
+	}
+
+	uri, err := url.Parse(src.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	doc, err := fetchCached(src, opts, false, func() (Document, error) {
+		content, err := getHTTP(uri.String(), opts)
+		if err != nil {
+			return Document{}, err
+		}
+		return Document{Source: src, Name: src.Location, Content: content, Resolved: uri.String()}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []Document{doc}, nil
+}
+
+// getHTTP downloads a URL with the configured client and any credentials for its host.
+func getHTTP(rawURL string, opts Options) ([]byte, error) {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	cred, ok, err := auth.Lookup(uri.Hostname(), opts.Auth)
+	if err != nil {
+		return nil, err
+	}
+	var header http.Header
+	if ok {
+		header = cred.HTTPHeader()
+	}
+
+	responseCode, content, err := opts.httpClient().FetchFromHTTPResource(uri, header)
+	if err != nil {
+		return nil, err
+	}
+	if responseCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code when downloading %s: %v", uri, responseCode)
+	}
+	return content, nil
+}
@@ -0,0 +1,83 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/component-generator/src/internal/archive"
+	"github.com/defenseunicorns/component-generator/src/internal/oscal"
+)
+
+// ZarfScheme identifies sources extracted from a Zarf package tarball.
+const ZarfScheme = "zarf"
+
+// maxDocumentSize bounds the files read from archives, keeping large payloads out of memory.
+const maxDocumentSize = 10 << 20
+
+func init() {
+	Register(ZarfScheme, FetcherFunc(fetchZarf))
+}
+
+// fetchZarf returns every component-definition in a Zarf package outside its images, matching the source path if set.
+func fetchZarf(src Source, opts Options) ([]Document, error) {
+	pkg := src.Location
+	if !filepath.IsAbs(pkg) {
+		pkg = filepath.Join(opts.BaseDirectory, pkg)
+	}
+	if src.Path != "" {
+		if _, err := path.Match(src.Path, ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", src.Path, err)
+		}
+	}
+
+	skip := func(name string) bool {
+		return strings.HasPrefix(name, "images/")
+	}
+
+	documents := []Document{}
+	err := archive.WalkFile(pkg, skip, func(name string, r io.Reader) error {
+		if !matchesArchivePath(src.Path, name) {
+			return nil
+		}
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml", ".json", ".xml":
+		default:
+			return nil
+		}
+
+		content, err := io.ReadAll(io.LimitReader(r, maxDocumentSize+1))
+		if err != nil {
+			return err
+		}
+		if len(content) > maxDocumentSize || !oscal.IsComponentDefinition(content) {
+			return nil
+		}
+		documents = append(documents, Document{Source: src, Name: src.Location + ":" + name, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("no OSCAL component definitions found in Zarf package %s", src.Location)
+	}
+
+	sort.Slice(documents, func(i, j int) bool { return documents[i].Name < documents[j].Name })
+	return documents, nil
+}
+
+// matchesArchivePath reports whether a file's path, or its name for patterns without '/', matches a pattern.
+func matchesArchivePath(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}